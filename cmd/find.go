@@ -28,12 +28,13 @@ var (
 		SilenceUsage:  true,
 		Args:          cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
 			dir := findCmdDirectory
 			fileBytes, err := os.ReadFile(internal.FileResolvePath(dir, ".git-ops-update.yaml"))
 			if err != nil {
 				return fmt.Errorf("unable to initialize: %w", err)
 			}
-			config, err := internal.LoadConfig(fileBytes)
+			config, err := internal.LoadConfig(dir, fileBytes)
 			if err != nil {
 				return fmt.Errorf("unable to load configuration: %w", err)
 			}
@@ -61,7 +62,7 @@ var (
 			}
 			cacheFile := internal.FileResolvePath(dir, ".git-ops-update.cache.yaml")
 			cacheProvider := internal.FileCacheProvider{File: cacheFile}
-			result := internal.DetectUpdates(dir, *config, cacheProvider)
+			result := internal.DetectUpdates(ctx, dir, *config, cacheProvider)
 			errorCount := 0
 
 			if findCmdDry {
@@ -121,7 +122,10 @@ var (
 				})...)
 
 				for _, t := range tasks {
-					err := internal.ApplyUpdate(dir, *config, cacheProvider, t.action, t.changeSet)
+					if ctx.Err() != nil {
+						return fmt.Errorf("aborted: %w", ctx.Err())
+					}
+					err := internal.ApplyUpdate(ctx, dir, *config, cacheProvider, t.action, t.changeSet)
 					if err != nil {
 						errorCount += 1
 						internal.LogError("%v", err)
@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
 	"github.com/airfocusio/git-ops-update/internal"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
@@ -31,7 +36,11 @@ var (
 )
 
 func Execute() error {
-	return rootCmd.Execute()
+	// cancelling the context on SIGINT/SIGTERM allows running updates to
+	// clean up after themselves instead of leaving half written branches
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return rootCmd.ExecuteContext(ctx)
 }
 
 func init() {
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -8,7 +9,7 @@ import (
 
 type Action interface {
 	Identifier() string
-	Apply(dir string, changeSet ChangeSet) error
+	Apply(ctx context.Context, dir string, changeSet ChangeSet) error
 }
 
 var _ Action = (*PushAction)(nil)
@@ -21,11 +22,11 @@ func (c PushAction) Identifier() string {
 	return "push"
 }
 
-func (a PushAction) Apply(dir string, changeSet ChangeSet) error {
+func (a PushAction) Apply(ctx context.Context, dir string, changeSet ChangeSet) error {
 	execCallbacks := SliceMap(changeSet.Changes, func(c Change) func() error {
-		return execCallback(dir, c.Exec)
+		return execCallback(ctx, dir, c.Exec)
 	})
-	return a.git.Push(dir, changeSet, execCallbacks...)
+	return a.git.Push(ctx, dir, changeSet, execCallbacks...)
 }
 
 var _ Action = (*RequestAction)(nil)
@@ -38,11 +39,11 @@ func (c RequestAction) Identifier() string {
 	return "request"
 }
 
-func (a RequestAction) Apply(dir string, changeSet ChangeSet) error {
+func (a RequestAction) Apply(ctx context.Context, dir string, changeSet ChangeSet) error {
 	execCallbacks := SliceMap(changeSet.Changes, func(c Change) func() error {
-		return execCallback(dir, c.Exec)
+		return execCallback(ctx, dir, c.Exec)
 	})
-	return a.git.Request(dir, changeSet, execCallbacks...)
+	return a.git.Request(ctx, dir, changeSet, execCallbacks...)
 }
 
 func getAction(p GitProvider, actionName string) (*Action, error) {
@@ -60,7 +61,7 @@ func getAction(p GitProvider, actionName string) (*Action, error) {
 	}
 }
 
-func execCallback(dir string, execCmdAndArgs []string) func() error {
+func execCallback(ctx context.Context, dir string, execCmdAndArgs []string) func() error {
 	if len(execCmdAndArgs) == 0 {
 		return func() error { return nil }
 	}
@@ -68,7 +69,7 @@ func execCallback(dir string, execCmdAndArgs []string) func() error {
 		name := execCmdAndArgs[0]
 		args := execCmdAndArgs[1:]
 		LogDebug("Executing %s with args [%s]", name, strings.Join(args, ", "))
-		cmd := exec.Command(name, args...)
+		cmd := exec.CommandContext(ctx, name, args...)
 		cmd.Dir = dir
 		bytes, err := cmd.CombinedOutput()
 		if err != nil {
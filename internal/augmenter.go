@@ -1,5 +1,7 @@
 package internal
 
+import "context"
+
 type Augmenter interface {
-	RenderMessage(config Config, change Change) (string, string, error)
+	RenderMessage(ctx context.Context, config Config, change Change) (string, string, error)
 }
@@ -18,7 +18,7 @@ type GithubAugmenter struct {
 	AccessToken string
 }
 
-func (a GithubAugmenter) RenderMessage(config Config, change Change) (string, string, error) {
+func (a GithubAugmenter) RenderMessage(ctx context.Context, config Config, change Change) (string, string, error) {
 	registry, ok := config.Registries[change.RegistryName]
 	if !ok {
 		return "", "", nil
@@ -28,11 +28,11 @@ func (a GithubAugmenter) RenderMessage(config Config, change Change) (string, st
 		return "", "", nil
 	}
 
-	oldLabels, err := dockerRegistry.RetrieveLabels(change.ResourceName, change.OldVersion)
+	oldLabels, err := dockerRegistry.RetrieveLabels(ctx, change.ResourceName, change.OldVersion)
 	if err != nil {
 		return "", "", err
 	}
-	newLabels, err := dockerRegistry.RetrieveLabels(change.ResourceName, change.NewVersion)
+	newLabels, err := dockerRegistry.RetrieveLabels(ctx, change.ResourceName, change.NewVersion)
 	if err != nil {
 		return "", "", err
 	}
@@ -55,7 +55,6 @@ func (a GithubAugmenter) RenderMessage(config Config, change Change) (string, st
 		base := oldGithubCommitMatch[1]
 		head := newGithubCommitMatch[1]
 
-		ctx := context.Background()
 		client := github.NewClient(&http.Client{})
 		if a.AccessToken != "" {
 			ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: a.AccessToken})
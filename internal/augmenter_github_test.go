@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -17,7 +18,7 @@ func TestGithubAugmenterRenderMessage(t *testing.T) {
 	a := GithubAugmenter{}
 
 	t.Run("only second labeled", func(t *testing.T) {
-		m1, m2, err := a.RenderMessage(c, Change{
+		m1, m2, err := a.RenderMessage(context.Background(), c, Change{
 			RegistryName: "docker",
 			ResourceName: "airfocusio/git-ops-update-test",
 			OldVersion:   "docker-v2-manifest-v0.0.0",
@@ -34,7 +35,7 @@ func TestGithubAugmenterRenderMessage(t *testing.T) {
 	})
 
 	t.Run("both labeled", func(t *testing.T) {
-		m1, m2, err := a.RenderMessage(c, Change{
+		m1, m2, err := a.RenderMessage(context.Background(), c, Change{
 			RegistryName: "docker",
 			ResourceName: "airfocusio/git-ops-update-test",
 			OldVersion:   "docker-v2-manifest-v0.0.1",
@@ -8,11 +8,16 @@ import (
 )
 
 type CacheResource struct {
-	RegistryName string    `yaml:"registry"`
-	ResourceName string    `yaml:"resource"`
-	Versions     []string  `yaml:"versions"`
-	Timestamp    time.Time `yaml:"timestamp"`
-	CacheKey     string    `yaml:"cacheKey,omitempty"`
+	RegistryName string                     `yaml:"registry"`
+	ResourceName string                     `yaml:"resource"`
+	Versions     []string                   `yaml:"versions"`
+	Metadata     map[string]VersionMetadata `yaml:"metadata,omitempty"`
+	Digests      map[string]string          `yaml:"digests,omitempty"`
+	Endpoint     string                     `yaml:"endpoint,omitempty"`
+	Skipped      []string                   `yaml:"skipped,omitempty"`
+	LastUpdated  time.Time                  `yaml:"lastUpdated,omitempty"`
+	Timestamp    time.Time                  `yaml:"timestamp"`
+	CacheKey     string                     `yaml:"cacheKey,omitempty"`
 }
 
 type Cache struct {
@@ -2,7 +2,9 @@ package internal
 
 import (
 	"fmt"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,27 +18,140 @@ type RawConfigHttpCredentials struct {
 	Password string `yaml:"password"`
 }
 
+type RawConfigHttp struct {
+	Timeout      *time.Duration `yaml:"timeout"`
+	Retries      *int           `yaml:"retries"`
+	RetryBackoff *time.Duration `yaml:"retryBackoff"`
+}
+
+func (c RawConfigHttp) toHttpClientConfig() HttpClientConfig {
+	result := defaultHttpClientConfig
+	if c.Timeout != nil {
+		result.Timeout = *c.Timeout
+	}
+	if c.Retries != nil {
+		result.Retries = *c.Retries
+	}
+	if c.RetryBackoff != nil {
+		result.RetryBackoff = *c.RetryBackoff
+	}
+	return result
+}
+
 type RawConfigFiles struct {
 	Includes []string `yaml:"includes"`
 	Excludes []string `yaml:"excludes"`
 }
 
 type RawConfigRegistryDocker struct {
+	Interval     time.Duration                   `yaml:"interval"`
+	Url          string                          `yaml:"url"`
+	Credentials  RawConfigHttpCredentials        `yaml:"credentials"`
+	DockerConfig bool                            `yaml:"dockerConfig"`
+	Mirrors      []RawConfigRegistryDockerMirror `yaml:"mirrors"`
+	Http         RawConfigHttp                   `yaml:",inline"`
+}
+
+type RawConfigRegistryDockerMirror struct {
+	Url              string                   `yaml:"url"`
+	Credentials      RawConfigHttpCredentials `yaml:"credentials"`
+	DockerConfig     bool                     `yaml:"dockerConfig"`
+	RepositoryPrefix string                   `yaml:"repositoryPrefix"`
+}
+
+type RawConfigRegistryHelm struct {
 	Interval    time.Duration            `yaml:"interval"`
 	Url         string                   `yaml:"url"`
 	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	PlainHttp   bool                     `yaml:"plainHttp"`
+	Http        RawConfigHttp            `yaml:",inline"`
 }
 
-type RawConfigRegistryHelm struct {
+type RawConfigRegistryGitHubTag struct {
 	Interval    time.Duration            `yaml:"interval"`
 	Url         string                   `yaml:"url"`
 	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	AccessToken string                   `yaml:"accessToken"`
+	Http        RawConfigHttp            `yaml:",inline"`
 }
 
-type RawConfigRegistryGitHubTag struct {
+type RawConfigRegistryGitHubRelease struct {
+	Interval    time.Duration            `yaml:"interval"`
+	Url         string                   `yaml:"url"`
+	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	AccessToken string                   `yaml:"accessToken"`
+	Http        RawConfigHttp            `yaml:",inline"`
+}
+
+type RawConfigRegistryGitLabTag struct {
+	Interval    time.Duration `yaml:"interval"`
+	Url         string        `yaml:"url"`
+	AccessToken string        `yaml:"accessToken"`
+	Releases    bool          `yaml:"releases"`
+}
+
+type RawConfigRegistryGit struct {
+	Interval    time.Duration            `yaml:"interval"`
+	Url         string                   `yaml:"url"`
+	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+}
+
+type RawConfigRegistryHttpJson struct {
 	Interval    time.Duration            `yaml:"interval"`
 	Url         string                   `yaml:"url"`
+	Headers     map[string]string        `yaml:"headers"`
 	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	JsonPath    string                   `yaml:"jsonPath"`
+	Http        RawConfigHttp            `yaml:",inline"`
+}
+
+type RawConfigRegistryCommand struct {
+	Interval time.Duration `yaml:"interval"`
+	Command  []string      `yaml:"command"`
+	Dir      string        `yaml:"dir"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+type RawConfigRegistryStatic struct {
+	Interval time.Duration       `yaml:"interval"`
+	Versions map[string][]string `yaml:"versions"`
+	File     string              `yaml:"file"`
+}
+
+type RawConfigRegistryNpm struct {
+	Interval    time.Duration `yaml:"interval"`
+	Url         string        `yaml:"url"`
+	AccessToken string        `yaml:"accessToken"`
+	Http        RawConfigHttp `yaml:",inline"`
+}
+
+type RawConfigRegistryPypi struct {
+	Interval    time.Duration            `yaml:"interval"`
+	Url         string                   `yaml:"url"`
+	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	Http        RawConfigHttp            `yaml:",inline"`
+}
+
+type RawConfigRegistryGoProxy struct {
+	Interval          time.Duration            `yaml:"interval"`
+	Url               string                   `yaml:"url"`
+	Credentials       RawConfigHttpCredentials `yaml:"credentials"`
+	AllowIncompatible bool                     `yaml:"allowIncompatible"`
+	Http              RawConfigHttp            `yaml:",inline"`
+}
+
+type RawConfigRegistryTerraform struct {
+	Interval    time.Duration `yaml:"interval"`
+	Url         string        `yaml:"url"`
+	AccessToken string        `yaml:"accessToken"`
+	Http        RawConfigHttp `yaml:",inline"`
+}
+
+type RawConfigRegistryMaven struct {
+	Interval    time.Duration            `yaml:"interval"`
+	Url         string                   `yaml:"url"`
+	Credentials RawConfigHttpCredentials `yaml:"credentials"`
+	Http        RawConfigHttp            `yaml:",inline"`
 }
 
 type RawConfigPolicyExtractLexicographicStrategy struct {
@@ -59,11 +174,26 @@ type RawConfigPolicyExtractSemverStrategy struct {
 	PinPatch         bool   `yaml:"pinPatch"`
 	AllowPrereleases bool   `yaml:"allowPrereleases"`
 	Relaxed          bool   `yaml:"relaxed"`
+	Constraint       string `yaml:"constraint"`
+}
+
+type RawConfigPolicyExtractCalverStrategy struct {
+	Key      string `yaml:"key"`
+	Value    string `yaml:"value"`
+	Layout   string `yaml:"layout"`
+	PinYear  bool   `yaml:"pinYear"`
+	PinMonth bool   `yaml:"pinMonth"`
+	PinWeek  bool   `yaml:"pinWeek"`
+	PinDay   bool   `yaml:"pinDay"`
+	PinMajor bool   `yaml:"pinMajor"`
+	PinMinor bool   `yaml:"pinMinor"`
 }
 
 type RawConfigPolicy struct {
 	Pattern  string                   `yaml:"pattern"`
 	Extracts []map[string]interface{} `yaml:"extracts"`
+	MinAge   time.Duration            `yaml:"minAge"`
+	Step     string                   `yaml:"step"`
 }
 
 type RawConfigAugmenterGithub struct {
@@ -101,10 +231,18 @@ type RawConfigGit struct {
 	GitLab  *RawConfigGitGitLab `yaml:"gitLab"`
 }
 
+type RawConfigIgnore struct {
+	Registry string    `yaml:"registry"`
+	Resource string    `yaml:"resource"`
+	Versions []string  `yaml:"versions"`
+	Expires  time.Time `yaml:"expires"`
+}
+
 type RawConfig struct {
 	Files      RawConfigFiles                    `yaml:"files"`
 	Registries map[string]map[string]interface{} `yaml:"registries"`
 	Policies   map[string]RawConfigPolicy        `yaml:"policies"`
+	Ignore     []RawConfigIgnore                 `yaml:"ignore"`
 	Augmenters []map[string]interface{}          `yaml:"augmenters"`
 	Git        RawConfigGit                      `yaml:"git"`
 }
@@ -117,12 +255,18 @@ type ConfigFiles struct {
 type Config struct {
 	Files      ConfigFiles
 	Registries map[string]Registry
-	Policies   map[string]Policy
-	Augmenters []Augmenter
-	Git        Git
+	// RegistryConcurrency limits how many resources are fetched from a
+	// registry at the same time, defaulting to defaultRegistryConcurrency
+	RegistryConcurrency map[string]int
+	Policies            map[string]Policy
+	Ignore              []IgnoreRule
+	Augmenters          []Augmenter
+	Git                 Git
 }
 
-func LoadConfig(bytesRaw []byte) (*Config, error) {
+const defaultRegistryConcurrency = 4
+
+func LoadConfig(dir string, bytesRaw []byte) (*Config, error) {
 	var expansionTemp interface{}
 	err := yaml.Unmarshal(bytesRaw, &expansionTemp)
 	if err != nil {
@@ -162,6 +306,7 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 	}
 
 	registries := map[string]Registry{}
+	registryConcurrency := map[string]int{}
 	for rn, r := range config.Registries {
 		if !validateName(rn) {
 			return nil, fmt.Errorf("registry name %s is invalid", rn)
@@ -170,12 +315,34 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 		if !ok {
 			return nil, fmt.Errorf("registry %s is missing type", rn)
 		}
+		if c, ok := r["concurrency"]; ok {
+			ci, ok := parseConcurrency(c)
+			if !ok {
+				return nil, fmt.Errorf("registry %s has invalid concurrency %v", rn, c)
+			}
+			registryConcurrency[rn] = ci
+		}
 		if t == "docker" {
 			rp := RawConfigRegistryDocker{}
 			err := decode(r, &rp)
 			if err != nil {
 				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
 			}
+			var mirrors []DockerRegistryMirror
+			for i, m := range rp.Mirrors {
+				if m.Url == "" {
+					return nil, fmt.Errorf("registry %s mirror %d is missing url", rn, i)
+				}
+				mirrors = append(mirrors, DockerRegistryMirror{
+					Url: m.Url,
+					Credentials: HttpBasicCredentials{
+						Username: m.Credentials.Username,
+						Password: m.Credentials.Password,
+					},
+					DockerConfig:     m.DockerConfig,
+					RepositoryPrefix: m.RepositoryPrefix,
+				})
+			}
 			registries[rn] = DockerRegistry{
 				Interval: rp.Interval,
 				Url:      rp.Url,
@@ -183,6 +350,9 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 					Username: rp.Credentials.Username,
 					Password: rp.Credentials.Password,
 				},
+				DockerConfig: rp.DockerConfig,
+				Mirrors:      mirrors,
+				Http:         rp.Http.toHttpClientConfig(),
 			}
 		} else if t == "helm" {
 			rp := RawConfigRegistryHelm{}
@@ -197,6 +367,8 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 					Username: rp.Credentials.Username,
 					Password: rp.Credentials.Password,
 				},
+				PlainHttp: rp.PlainHttp,
+				Http:      rp.Http.toHttpClientConfig(),
 			}
 		} else if t == "git-hub-tag" {
 			rp := RawConfigRegistryGitHubTag{}
@@ -211,6 +383,177 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 					Username: rp.Credentials.Username,
 					Password: rp.Credentials.Password,
 				},
+				AccessToken: rp.AccessToken,
+				Http:        rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "git-hub-release" {
+			rp := RawConfigRegistryGitHubRelease{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = GitHubReleaseRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+				AccessToken: rp.AccessToken,
+				Http:        rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "git-lab-tag" {
+			rp := RawConfigRegistryGitLabTag{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = GitLabTagRegistry{
+				Interval:    rp.Interval,
+				Url:         rp.Url,
+				AccessToken: rp.AccessToken,
+				Releases:    rp.Releases,
+			}
+		} else if t == "git" {
+			rp := RawConfigRegistryGit{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = GitRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+			}
+		} else if t == "http-json" {
+			rp := RawConfigRegistryHttpJson{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			if rp.Url == "" {
+				return nil, fmt.Errorf("registry %s is missing url", rn)
+			}
+			if strings.TrimSpace(rp.JsonPath) == "" {
+				return nil, fmt.Errorf("registry %s is missing jsonPath", rn)
+			}
+			jsonPath, err := parseJsonPath(rp.JsonPath)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = HttpJsonRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Headers:  rp.Headers,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+				JsonPath: *jsonPath,
+				Http:     rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "command" {
+			rp := RawConfigRegistryCommand{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			if len(rp.Command) == 0 {
+				return nil, fmt.Errorf("registry %s is missing command", rn)
+			}
+			registries[rn] = CommandRegistry{
+				Interval: rp.Interval,
+				Command:  rp.Command,
+				Dir:      FileResolvePath(dir, rp.Dir),
+				Timeout:  rp.Timeout,
+			}
+		} else if t == "static" {
+			rp := RawConfigRegistryStatic{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			file := ""
+			if rp.File != "" {
+				file = FileResolvePath(dir, rp.File)
+			}
+			registries[rn] = StaticRegistry{
+				Interval: rp.Interval,
+				Versions: rp.Versions,
+				File:     file,
+			}
+		} else if t == "npm" {
+			rp := RawConfigRegistryNpm{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = NpmRegistry{
+				Interval:    rp.Interval,
+				Url:         rp.Url,
+				AccessToken: rp.AccessToken,
+				Http:        rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "pypi" {
+			rp := RawConfigRegistryPypi{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = PypiRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+				Http: rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "go-proxy" {
+			rp := RawConfigRegistryGoProxy{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = GoProxyRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+				AllowIncompatible: rp.AllowIncompatible,
+				Http:              rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "terraform" {
+			rp := RawConfigRegistryTerraform{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = TerraformRegistry{
+				Interval:    rp.Interval,
+				Url:         rp.Url,
+				AccessToken: rp.AccessToken,
+				Http:        rp.Http.toHttpClientConfig(),
+			}
+		} else if t == "maven" {
+			rp := RawConfigRegistryMaven{}
+			err := decode(r, &rp)
+			if err != nil {
+				return nil, fmt.Errorf("registry %s is invalid: %w", rn, err)
+			}
+			registries[rn] = MavenRegistry{
+				Interval: rp.Interval,
+				Url:      rp.Url,
+				Credentials: HttpBasicCredentials{
+					Username: rp.Credentials.Username,
+					Password: rp.Credentials.Password,
+				},
+				Http: rp.Http.toHttpClientConfig(),
 			}
 		} else {
 			return nil, fmt.Errorf("registry %s has invalid type %s", rn, t)
@@ -253,12 +596,42 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 				if err != nil {
 					return nil, fmt.Errorf("policy extract %s/%d is invalid: %w", pn, ei, err)
 				}
+				var constraint *SemverConstraint
+				if ep.Constraint != "" {
+					constraint, err = ParseSemverConstraint(ep.Constraint)
+					if err != nil {
+						return nil, fmt.Errorf("policy extract %s/%d is invalid: %w", pn, ei, err)
+					}
+				}
 				extracts = append(extracts, Extract{Key: ep.Key, Value: ep.Value, Strategy: SemverExtractStrategy{
 					PinMajor:         ep.PinMajor,
 					PinMinor:         ep.PinMinor,
 					PinPatch:         ep.PinPatch,
 					AllowPrereleases: ep.AllowPrereleases,
 					Relaxed:          ep.Relaxed,
+					Constraint:       constraint,
+				}})
+			} else if t == "calver" {
+				ep := RawConfigPolicyExtractCalverStrategy{}
+				err := decode(e, &ep)
+				if err != nil {
+					return nil, fmt.Errorf("policy extract %s/%d is invalid: %w", pn, ei, err)
+				}
+				if ep.Layout == "" {
+					return nil, fmt.Errorf("policy extract %s/%d is missing layout", pn, ei)
+				}
+				layout, err := ParseCalverLayout(ep.Layout)
+				if err != nil {
+					return nil, fmt.Errorf("policy extract %s/%d is invalid: %w", pn, ei, err)
+				}
+				extracts = append(extracts, Extract{Key: ep.Key, Value: ep.Value, Strategy: CalverExtractStrategy{
+					Layout:   *layout,
+					PinYear:  ep.PinYear,
+					PinMonth: ep.PinMonth,
+					PinWeek:  ep.PinWeek,
+					PinDay:   ep.PinDay,
+					PinMajor: ep.PinMajor,
+					PinMinor: ep.PinMinor,
 				}})
 			} else {
 				return nil, fmt.Errorf("policy %s/%d has invalid type %s", pn, ei, t)
@@ -274,10 +647,47 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 		if p.Pattern == "" {
 			pattern = nil
 		}
+		if p.MinAge < 0 {
+			return nil, fmt.Errorf("policy %s minAge must not be negative", pn)
+		}
+		if p.Step != "" && p.Step != PolicyStepMinor && p.Step != PolicyStepMajor {
+			return nil, fmt.Errorf("policy %s has invalid step %s", pn, p.Step)
+		}
 		policies[pn] = Policy{
 			Pattern:  pattern,
 			Extracts: extracts,
+			MinAge:   p.MinAge,
+			Step:     p.Step,
+		}
+	}
+
+	ignore := []IgnoreRule{}
+	for ii, i := range config.Ignore {
+		if i.Registry != "" {
+			if _, ok := registries[i.Registry]; !ok {
+				return nil, fmt.Errorf("ignore rule %d references unknown registry %s", ii, i.Registry)
+			}
 		}
+		if _, err := path.Match(i.Resource, ""); err != nil {
+			return nil, fmt.Errorf("ignore rule %d resource %s is invalid: %w", ii, i.Resource, err)
+		}
+		if len(i.Versions) == 0 {
+			return nil, fmt.Errorf("ignore rule %d has no versions", ii)
+		}
+		versions := []VersionPattern{}
+		for _, v := range i.Versions {
+			pattern, err := ParseVersionPattern(v)
+			if err != nil {
+				return nil, fmt.Errorf("ignore rule %d is invalid: %w", ii, err)
+			}
+			versions = append(versions, *pattern)
+		}
+		ignore = append(ignore, IgnoreRule{
+			Registry: i.Registry,
+			Resource: i.Resource,
+			Versions: versions,
+			Expires:  i.Expires,
+		})
 	}
 
 	augmenters := []Augmenter{}
@@ -349,13 +759,26 @@ func LoadConfig(bytesRaw []byte) (*Config, error) {
 			Includes: fileIncludes,
 			Excludes: fileExcludes,
 		},
-		Registries: registries,
-		Policies:   policies,
-		Augmenters: augmenters,
-		Git:        git,
+		Registries:          registries,
+		RegistryConcurrency: registryConcurrency,
+		Policies:            policies,
+		Ignore:              ignore,
+		Augmenters:          augmenters,
+		Git:                 git,
 	}, nil
 }
 
+// parseConcurrency accepts numeric strings as well, as values expanded from
+// environment variables are always strings.
+func parseConcurrency(value interface{}) (int, bool) {
+	result, ok := value.(int)
+	if str, isStr := value.(string); isStr {
+		n, err := strconv.Atoi(strings.TrimSpace(str))
+		result, ok = n, err == nil
+	}
+	return result, ok && result > 0
+}
+
 func decode(input interface{}, output interface{}) error {
 	bytes, err := yaml.Marshal(input)
 	if err != nil {
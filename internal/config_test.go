@@ -13,7 +13,14 @@ func TestLoadConfig(t *testing.T) {
 	bytes, err := os.ReadFile("config_test.yaml")
 	assert.NoError(t, err)
 
-	c1, err := LoadConfig(bytes)
+	c1, err := LoadConfig("/repo", bytes)
+	assert.NoError(t, err)
+
+	cooldownConstraint, err := ParseSemverConstraint(">=1.24 <1.28")
+	assert.NoError(t, err)
+	ignoreConstraint, err := ParseSemverConstraint(">=1.27 <1.27.3")
+	assert.NoError(t, err)
+	calverLayout, err := ParseCalverLayout("YY.0M.MICRO")
 	assert.NoError(t, err)
 
 	c2 := Config{
@@ -29,6 +36,32 @@ func TestLoadConfig(t *testing.T) {
 					Username: "user",
 					Password: "pass",
 				},
+				Http: HttpClientConfig{
+					Timeout:      10 * time.Second,
+					Retries:      5,
+					RetryBackoff: 2 * time.Second,
+				},
+			},
+			"docker-config": DockerRegistry{
+				Interval:     time.Duration(60000000000),
+				Url:          "https://ghcr.io",
+				DockerConfig: true,
+				Http:         defaultHttpClientConfig,
+			},
+			"docker-mirrors": DockerRegistry{
+				Interval: time.Duration(60000000000),
+				Url:      "https://registry-1.docker.io",
+				Mirrors: []DockerRegistryMirror{
+					{
+						Url: "https://harbor.example.com",
+						Credentials: HttpBasicCredentials{
+							Username: "user",
+							Password: "pass",
+						},
+						RepositoryPrefix: "dockerhub-proxy",
+					},
+				},
+				Http: defaultHttpClientConfig,
 			},
 			"helm": HelmRegistry{
 				Interval: time.Duration(3600000000000),
@@ -37,6 +70,7 @@ func TestLoadConfig(t *testing.T) {
 					Username: "user",
 					Password: "pass",
 				},
+				Http: defaultHttpClientConfig,
 			},
 			"git-hub": GitHubTagRegistry{
 				Interval: time.Duration(3600000000000),
@@ -45,8 +79,99 @@ func TestLoadConfig(t *testing.T) {
 					Username: "user",
 					Password: "pass",
 				},
+				AccessToken: "access_token",
+				Http:        defaultHttpClientConfig,
+			},
+			"git-hub-release": GitHubReleaseRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://api.github-enterprise.com",
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+				Http: defaultHttpClientConfig,
+			},
+			"git-lab": GitLabTagRegistry{
+				Interval:    time.Duration(3600000000000),
+				Url:         "https://gitlab.example.com",
+				AccessToken: "access_token",
+				Releases:    true,
+			},
+			"git": GitRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://git.example.com",
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+			},
+			"http-json": HttpJsonRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://example.com/<resource>/releases.json",
+				Headers:  map[string]string{"X-Api-Key": "key"},
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+				JsonPath: jsonPath{
+					Segments: []jsonPathSegment{{Key: "releases"}, {Wildcard: true}, {Key: "version"}},
+				},
+				Http: defaultHttpClientConfig,
+			},
+			"command": CommandRegistry{
+				Interval: time.Duration(3600000000000),
+				Command:  []string{"./versions.sh", "<resource>"},
+				Dir:      "/repo/scripts",
+				Timeout:  time.Duration(30000000000),
+			},
+			"static": StaticRegistry{
+				Versions: map[string][]string{"library/nginx": {"1.25.0", "1.25.1"}},
+				File:     "/repo/approved-versions.yaml",
+			},
+			"npm": NpmRegistry{
+				Interval:    time.Duration(3600000000000),
+				Url:         "https://npm.example.com",
+				AccessToken: "access_token",
+				Http:        defaultHttpClientConfig,
+			},
+			"pypi": PypiRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://pypi.example.com",
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+				Http: defaultHttpClientConfig,
+			},
+			"go-proxy": GoProxyRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://goproxy.example.com",
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+				AllowIncompatible: true,
+				Http:              defaultHttpClientConfig,
+			},
+			"terraform": TerraformRegistry{
+				Interval:    time.Duration(3600000000000),
+				Url:         "https://terraform.example.com",
+				AccessToken: "access_token",
+				Http:        defaultHttpClientConfig,
+			},
+			"maven": MavenRegistry{
+				Interval: time.Duration(3600000000000),
+				Url:      "https://maven.example.com",
+				Credentials: HttpBasicCredentials{
+					Username: "user",
+					Password: "pass",
+				},
+				Http: defaultHttpClientConfig,
 			},
 		},
+		RegistryConcurrency: map[string]int{
+			"docker-config": 2,
+		},
 		Policies: map[string]Policy{
 			"lexicographic": {
 				Pattern: regexp.MustCompile(`^(?P<all>.*)$`),
@@ -85,6 +210,40 @@ func TestLoadConfig(t *testing.T) {
 					},
 				},
 			},
+			"cooldown": {
+				Extracts: []Extract{
+					{
+						Strategy: SemverExtractStrategy{
+							Constraint: cooldownConstraint,
+						},
+					},
+				},
+				MinAge: 72 * time.Hour,
+				Step:   PolicyStepMinor,
+			},
+			"calver": {
+				Extracts: []Extract{
+					{
+						Key: "calver",
+						Strategy: CalverExtractStrategy{
+							Layout:  *calverLayout,
+							PinYear: true,
+						},
+					},
+				},
+			},
+		},
+		Ignore: []IgnoreRule{
+			{
+				Registry: "docker",
+				Resource: "library/*",
+				Versions: []VersionPattern{
+					{Glob: "1.25.0"},
+					{Regexp: regexp.MustCompile(`^1\.26\.`)},
+					{Constraint: ignoreConstraint},
+				},
+				Expires: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
 		},
 		Augmenters: []Augmenter{
 			GithubAugmenter{
@@ -109,7 +268,55 @@ func TestLoadConfig(t *testing.T) {
 
 	assert.Equal(t, c2.Files, c1.Files)
 	assert.Equal(t, c2.Registries, c1.Registries)
+	assert.Equal(t, c2.RegistryConcurrency, c1.RegistryConcurrency)
+	cooldown, ok := c1.Policies["cooldown"].Extracts[0].Strategy.(SemverExtractStrategy)
+	if assert.True(t, ok) && assert.NotNil(t, cooldown.Constraint) {
+		assert.Equal(t, cooldownConstraint.Raw, cooldown.Constraint.Raw)
+		cooldown.Constraint = cooldownConstraint
+		c1.Policies["cooldown"].Extracts[0].Strategy = cooldown
+	}
 	assert.Equal(t, c2.Policies, c1.Policies)
 	assert.Equal(t, c2.Augmenters, c1.Augmenters)
 	assert.Equal(t, c2.Git, c1.Git)
 }
+
+func TestLoadConfigConcurrencyFromEnv(t *testing.T) {
+	t.Setenv("GIT_OPS_UPDATE_TEST_CONCURRENCY", "3")
+	config, err := LoadConfig("/repo", []byte(`
+registries:
+  docker:
+    type: docker
+    url: https://registry-1.docker.io
+    concurrency: ${GIT_OPS_UPDATE_TEST_CONCURRENCY}
+`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]int{"docker": 3}, config.RegistryConcurrency)
+	}
+
+	_, err = LoadConfig("/repo", []byte(`
+registries:
+  docker:
+    type: docker
+    url: https://registry-1.docker.io
+    concurrency: many
+`))
+	assert.Error(t, err)
+}
+
+func TestLoadConfigHttpJsonRequiresJsonPath(t *testing.T) {
+	_, err := LoadConfig("/repo", []byte(`
+registries:
+  vendor:
+    type: http-json
+    url: https://example.com/releases.json
+`))
+	assert.EqualError(t, err, "registry vendor is missing jsonPath")
+
+	_, err = LoadConfig("/repo", []byte(`
+registries:
+  vendor:
+    type: http-json
+    jsonPath: $.releases[*].version
+`))
+	assert.EqualError(t, err, "registry vendor is missing url")
+}
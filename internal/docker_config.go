@@ -0,0 +1,196 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const dockerConfigHubServer = "https://index.docker.io/v1/"
+
+// dockerConfigHelperTimeout bounds how long a credential helper may run, so
+// that a helper waiting for user interaction does not block forever
+var dockerConfigHelperTimeout = 30 * time.Second
+
+type dockerConfigCacheKey struct {
+	file        string
+	registryUrl string
+}
+
+// dockerConfigCacheEntry resolves the credentials of a single registry once,
+// so that concurrent lookups for the same registry wait for each other while
+// lookups for other registries are not blocked by a slow helper
+type dockerConfigCacheEntry struct {
+	once        sync.Once
+	credentials HttpBasicCredentials
+	err         error
+}
+
+// dockerConfigCache keeps resolved credentials and errors for the whole run,
+// so that the config is read and helpers are executed only once per registry
+var dockerConfigCache = struct {
+	sync.Mutex
+	entries map[dockerConfigCacheKey]*dockerConfigCacheEntry
+}{entries: map[dockerConfigCacheKey]*dockerConfigCacheEntry{}}
+
+type dockerConfigFile struct {
+	Auths       map[string]dockerConfigAuth `json:"auths"`
+	CredHelpers map[string]string           `json:"credHelpers"`
+	CredsStore  string                      `json:"credsStore"`
+}
+
+type dockerConfigAuth struct {
+	Auth     string `json:"auth"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type dockerConfigHelperCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// dockerConfigPath returns the location of the docker cli config file, which
+// can be relocated with the DOCKER_CONFIG environment variable.
+func dockerConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// dockerConfigLoadCredentials resolves the credentials for the given registry
+// url the same way as the docker cli does: a registry specific credential
+// helper wins over the global credential store, which wins over the static
+// auths. No credentials and no error are returned if nothing matches.
+// Lookups, including failed ones, are cached for the rest of the run.
+func dockerConfigLoadCredentials(ctx context.Context, registryUrl string) (HttpBasicCredentials, error) {
+	file, err := dockerConfigPath()
+	if err != nil {
+		return HttpBasicCredentials{}, err
+	}
+	key := dockerConfigCacheKey{file: file, registryUrl: registryUrl}
+	dockerConfigCache.Lock()
+	entry, ok := dockerConfigCache.entries[key]
+	if !ok {
+		entry = &dockerConfigCacheEntry{}
+		dockerConfigCache.entries[key] = entry
+	}
+	dockerConfigCache.Unlock()
+	entry.once.Do(func() {
+		entry.credentials, entry.err = dockerConfigReadCredentials(ctx, file, registryUrl)
+	})
+	return entry.credentials, entry.err
+}
+
+func dockerConfigReadCredentials(ctx context.Context, file string, registryUrl string) (HttpBasicCredentials, error) {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		LogDebug("Docker config %s does not exist", file)
+		return HttpBasicCredentials{}, nil
+	}
+	if err != nil {
+		return HttpBasicCredentials{}, err
+	}
+	config := dockerConfigFile{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return HttpBasicCredentials{}, fmt.Errorf("docker config %s is invalid: %w", file, err)
+	}
+
+	host := dockerConfigServerHost(registryUrl)
+	server := host
+	if host == "docker.io" || host == "index.docker.io" || host == "registry-1.docker.io" {
+		server = dockerConfigHubServer
+	}
+
+	// the docker cli keys docker hub helpers by its legacy server url
+	for _, key := range []string{host, server} {
+		if helper, ok := config.CredHelpers[key]; ok && helper != "" {
+			return dockerConfigRunHelper(ctx, helper, server)
+		}
+	}
+	if config.CredsStore != "" {
+		credentials, err := dockerConfigRunHelper(ctx, config.CredsStore, server)
+		if err != nil || credentials.Username != "" {
+			return credentials, err
+		}
+	}
+	for key, auth := range config.Auths {
+		if key != server && dockerConfigServerHost(key) != host {
+			continue
+		}
+		if auth.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+			if err != nil {
+				return HttpBasicCredentials{}, fmt.Errorf("docker config auth for %s is invalid: %w", key, err)
+			}
+			username, password, ok := strings.Cut(string(decoded), ":")
+			if !ok {
+				return HttpBasicCredentials{}, fmt.Errorf("docker config auth for %s is invalid", key)
+			}
+			return HttpBasicCredentials{Username: username, Password: password}, nil
+		}
+		if auth.Username != "" {
+			return HttpBasicCredentials{Username: auth.Username, Password: auth.Password}, nil
+		}
+	}
+	return HttpBasicCredentials{}, nil
+}
+
+func dockerConfigRunHelper(ctx context.Context, helper string, server string) (HttpBasicCredentials, error) {
+	name := "docker-credential-" + helper
+	LogDebug("Retrieving credentials for %s from %s", server, name)
+	ctx, cancel := context.WithTimeout(ctx, dockerConfigHelperTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, "get")
+	// do not wait for children of the helper that keep the output open
+	cmd.WaitDelay = time.Second
+	cmd.Stdin = strings.NewReader(server)
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return HttpBasicCredentials{}, fmt.Errorf("executing %s did not finish within %v", name, dockerConfigHelperTimeout)
+	}
+	if err != nil {
+		output := strings.TrimSpace(stdout.String() + stderr.String())
+		if strings.Contains(output, "credentials not found") {
+			return HttpBasicCredentials{}, nil
+		}
+		return HttpBasicCredentials{}, fmt.Errorf("executing %s failed: %w\n%s", name, err, output)
+	}
+	credentials := dockerConfigHelperCredentials{}
+	err = json.Unmarshal(stdout.Bytes(), &credentials)
+	if err != nil {
+		return HttpBasicCredentials{}, fmt.Errorf("output of %s is invalid: %w", name, err)
+	}
+	return HttpBasicCredentials{Username: credentials.Username, Password: credentials.Secret}, nil
+}
+
+func dockerConfigServerHost(server string) string {
+	if !strings.Contains(server, "://") {
+		server = "https://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return server
+	}
+	return u.Host
+}
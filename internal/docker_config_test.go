@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDockerConfigLoadCredentials(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	helper := `#!/bin/sh
+read server
+if [ "$server" = "ghcr.io" ]; then
+  echo '{"ServerURL":"ghcr.io","Username":"helper-user","Secret":"helper-pass"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0o755))
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "aHViLXVzZXI6aHViLXBhc3M="},
+    "registry.example.com": {"username": "plain-user", "password": "plain-pass"}
+  },
+  "credHelpers": {
+    "ghcr.io": "test"
+  },
+  "credsStore": "test"
+}`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o644))
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	credentials, err := dockerConfigLoadCredentials(context.Background(), "https://ghcr.io")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{Username: "helper-user", Password: "helper-pass"}, credentials)
+	}
+	credentials, err = dockerConfigLoadCredentials(context.Background(), "https://registry-1.docker.io")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{Username: "hub-user", Password: "hub-pass"}, credentials)
+	}
+	credentials, err = dockerConfigLoadCredentials(context.Background(), "https://registry.example.com/")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{Username: "plain-user", Password: "plain-pass"}, credentials)
+	}
+	credentials, err = dockerConfigLoadCredentials(context.Background(), "https://unknown.example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{}, credentials)
+	}
+
+	hubDir := filepath.Join(dir, "hub")
+	assert.NoError(t, os.Mkdir(hubDir, 0o755))
+	hubHelper := `#!/bin/sh
+read server
+if [ "$server" = "https://index.docker.io/v1/" ]; then
+  echo '{"ServerURL":"https://index.docker.io/v1/","Username":"hub-helper-user","Secret":"hub-helper-pass"}'
+else
+  echo "credentials not found in native keychain"
+  exit 1
+fi
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-hub"), []byte(hubHelper), 0o755))
+	hubConfig := `{
+  "credHelpers": {
+    "https://index.docker.io/v1/": "hub"
+  }
+}`
+	assert.NoError(t, os.WriteFile(filepath.Join(hubDir, "config.json"), []byte(hubConfig), 0o644))
+	t.Setenv("DOCKER_CONFIG", hubDir)
+	credentials, err = dockerConfigLoadCredentials(context.Background(), "https://registry-1.docker.io")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{Username: "hub-helper-user", Password: "hub-helper-pass"}, credentials)
+	}
+
+	t.Setenv("DOCKER_CONFIG", filepath.Join(dir, "missing"))
+	credentials, err = dockerConfigLoadCredentials(context.Background(), "https://ghcr.io")
+	if assert.NoError(t, err) {
+		assert.Equal(t, HttpBasicCredentials{}, credentials)
+	}
+}
+
+func TestDockerConfigLoadCredentialsCached(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	helper := `#!/bin/sh
+read server
+echo "$server" >> "$(dirname "$0")/calls"
+if [ "$server" = "ghcr.io" ]; then
+  echo '{"ServerURL":"ghcr.io","Username":"helper-user","Secret":"helper-pass"}'
+else
+  echo "helper is broken"
+  exit 1
+fi
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0o755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"credsStore": "test"}`), 0o644))
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			credentials, err := dockerConfigLoadCredentials(context.Background(), "https://ghcr.io")
+			if assert.NoError(t, err) {
+				assert.Equal(t, HttpBasicCredentials{Username: "helper-user", Password: "helper-pass"}, credentials)
+			}
+		}()
+	}
+	wg.Wait()
+	for i := 0; i < 3; i++ {
+		_, err := dockerConfigLoadCredentials(context.Background(), "https://quay.io")
+		assert.Error(t, err)
+	}
+	calls, err := os.ReadFile(filepath.Join(dir, "calls"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "ghcr.io\nquay.io\n", string(calls))
+	}
+}
+
+func TestDockerConfigRunHelperTimeout(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	helper := `#!/bin/sh
+exec sleep 10
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-hang"), []byte(helper), 0o755))
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	dockerConfigHelperTimeout = 100 * time.Millisecond
+	defer func() { dockerConfigHelperTimeout = 30 * time.Second }()
+
+	start := time.Now()
+	_, err = dockerConfigRunHelper(context.Background(), "hang", "ghcr.io")
+	if assert.Error(t, err) {
+		assert.True(t, strings.Contains(err.Error(), "did not finish"))
+	}
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
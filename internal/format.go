@@ -44,6 +44,61 @@ func (f DockerImageFormat) ReplaceVersion(str string, version string) (*string,
 	return &result, nil
 }
 
+// DigestFormat is implemented by formats that pin a content digest next to
+// the version, which then has to be replaced together with the version.
+type DigestFormat interface {
+	Format
+	ExtractDigest(str string) (*string, error)
+	ReplaceVersionWithDigest(str string, version string, digest string) (*string, error)
+}
+
+var _ DigestFormat = (*DockerImageDigestFormat)(nil)
+
+type DockerImageDigestFormat struct{}
+
+func (f DockerImageDigestFormat) ExtractVersion(str string) (*string, error) {
+	_, tag, _, err := f.split(str)
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (f DockerImageDigestFormat) ExtractDigest(str string) (*string, error) {
+	_, _, digest, err := f.split(str)
+	if err != nil {
+		return nil, err
+	}
+	return &digest, nil
+}
+
+func (f DockerImageDigestFormat) ReplaceVersion(str string, version string) (*string, error) {
+	return nil, fmt.Errorf("value %s in docker-image-digest format can only be replaced together with a digest", str)
+}
+
+func (f DockerImageDigestFormat) ReplaceVersionWithDigest(str string, version string, digest string) (*string, error) {
+	image, _, _, err := f.split(str)
+	if err != nil {
+		return nil, err
+	}
+	result := image + ":" + version + "@" + digest
+	return &result, nil
+}
+
+// split separates image, tag and digest of values like
+// registry:5000/image:tag@sha256:abc, where the digest may be missing
+func (f DockerImageDigestFormat) split(str string) (string, string, string, error) {
+	ref, digest, _ := strings.Cut(str, "@")
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i+1:], "/") || ref[i+1:] == "" {
+		return "", "", "", fmt.Errorf("value %s is not a in a valid docker-image-digest format", str)
+	}
+	if digest != "" && !strings.Contains(digest, ":") {
+		return "", "", "", fmt.Errorf("value %s is not a in a valid docker-image-digest format", str)
+	}
+	return ref[:i], ref[i+1:], digest, nil
+}
+
 var _ Format = (*RegexpFormat)(nil)
 
 type RegexpFormat struct {
@@ -92,6 +147,10 @@ func getFormat(formatName string) (*Format, error) {
 		format := Format(DockerImageFormat{})
 		return &format, nil
 	}
+	if formatName == "docker-image-digest" {
+		format := Format(DockerImageDigestFormat{})
+		return &format, nil
+	}
 	if strings.HasPrefix(formatName, "regexp:") {
 		pattern, err := regexp.Compile(strings.TrimPrefix(formatName, "regexp:"))
 		if err != nil {
@@ -49,6 +49,57 @@ func TestDockerImageFormat(t *testing.T) {
 	}
 }
 
+func TestDockerImageDigestFormat(t *testing.T) {
+	format := DockerImageDigestFormat{}
+
+	_, err := format.ExtractVersion("")
+	assert.Error(t, err)
+
+	_, err = format.ExtractVersion("any")
+	assert.Error(t, err)
+
+	_, err = format.ExtractVersion("registry:5000/image@sha256:abc")
+	assert.Error(t, err)
+
+	actual, err := format.ExtractVersion("image:version@sha256:abc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "version", *actual)
+	}
+
+	actual, err = format.ExtractVersion("registry:5000/image:version@sha256:abc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "version", *actual)
+	}
+
+	actual, err = format.ExtractVersion("image:version")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "version", *actual)
+	}
+
+	actual, err = format.ExtractDigest("registry:5000/image:version@sha256:abc")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sha256:abc", *actual)
+	}
+
+	actual, err = format.ExtractDigest("image:version")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "", *actual)
+	}
+
+	_, err = format.ReplaceVersion("image:version@sha256:abc", "next")
+	assert.Error(t, err)
+
+	actual, err = format.ReplaceVersionWithDigest("registry:5000/image:version@sha256:abc", "next", "sha256:def")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "registry:5000/image:next@sha256:def", *actual)
+	}
+
+	actual, err = format.ReplaceVersionWithDigest("image:version", "next", "sha256:def")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "image:next@sha256:def", *actual)
+	}
+}
+
 func TestRegexpFormatTest(t *testing.T) {
 	format := RegexpFormat{Pattern: *regexp.MustCompile(`^https://domain\.com/(?P<version>[^/]+)/dist$`)}
 	format2 := RegexpFormat{Pattern: *regexp.MustCompile(`^https://domain\.com/(?P<version>[^/]+)/dist/(?P<version>[^/]+).tar$`)}
@@ -1,14 +1,17 @@
 package internal
 
 import (
+	"context"
 	"time"
 
 	"github.com/ProtonMail/go-crypto/openpgp"
 	"github.com/go-git/go-billy/v5/osfs"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 type Git struct {
@@ -16,8 +19,8 @@ type Git struct {
 }
 
 type GitProvider interface {
-	Push(dir string, changeSet ChangeSet, callbacks ...func() error) error
-	Request(dir string, changeSet ChangeSet, callbacks ...func() error) error
+	Push(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error
+	Request(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error
 }
 
 type GitAuthor struct {
@@ -28,6 +31,8 @@ type GitAuthor struct {
 
 const branchPrefix = "git-ops-update"
 
+const gitRollbackTimeout = 30 * time.Second
+
 func applyChangesAsCommit(worktree git.Worktree, dir string, changeSet ChangeSet, message string, author GitAuthor, callbacks ...func() error) (*plumbing.Hash, error) {
 	err := changeSet.Push(dir)
 	if err != nil {
@@ -68,3 +73,33 @@ func applyChangesAsCommit(worktree git.Worktree, dir string, changeSet ChangeSet
 
 	return &commit, nil
 }
+
+// rollbackRequestBranch restores the base branch after creating a request
+// failed or got cancelled halfway, so that neither the worktree nor the
+// remote are left with a half written target branch.
+func rollbackRequestBranch(repo *git.Repository, worktree *git.Worktree, baseBranch plumbing.ReferenceName, targetBranch plumbing.ReferenceName, auth transport.AuthMethod, pushed bool) {
+	LogDebug("Rolling back git branch %s", targetBranch.Short())
+	if pushed {
+		// the original context might already be cancelled
+		ctx, cancel := context.WithTimeout(context.Background(), gitRollbackTimeout)
+		defer cancel()
+		err := repo.PushContext(ctx, &git.PushOptions{
+			Auth: auth,
+			RefSpecs: []config.RefSpec{
+				config.RefSpec(":" + targetBranch.String()),
+			},
+		})
+		if err != nil {
+			LogWarning("Unable to remove remote branch %s: %v", targetBranch.Short(), err)
+		}
+	}
+	err := worktree.Checkout(&git.CheckoutOptions{Branch: baseBranch, Force: true})
+	if err != nil {
+		LogWarning("Unable to checkout to base branch %s: %v", baseBranch.Short(), err)
+		return
+	}
+	err = repo.Storer.RemoveReference(targetBranch)
+	if err != nil {
+		LogWarning("Unable to remove branch %s: %v", targetBranch.Short(), err)
+	}
+}
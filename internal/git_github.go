@@ -30,7 +30,7 @@ type GitHubGitProvider struct {
 	InheritLabels GitHubGitProviderInheritLabels
 }
 
-func (p GitHubGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p GitHubGitProvider) Push(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
 		return fmt.Errorf("unable to open git repository: %w", err)
@@ -45,7 +45,7 @@ func (p GitHubGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...fu
 	if err != nil {
 		return fmt.Errorf("unable to commit changes: %w", err)
 	}
-	err = repo.Push(&git.PushOptions{
+	err = repo.PushContext(ctx, &git.PushOptions{
 		Auth: &http.BasicAuth{
 			Username: "api",
 			Password: p.AccessToken,
@@ -57,7 +57,7 @@ func (p GitHubGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...fu
 	return nil
 }
 
-func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p GitHubGitProvider) Request(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) (err error) {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
 		return fmt.Errorf("unable to open git repository: %w", err)
@@ -70,11 +70,12 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	if err != nil {
 		return fmt.Errorf("unable to get git remote origin: %w", err)
 	}
-	remoteRefs, err := remote.List(&git.ListOptions{
-		Auth: &http.BasicAuth{
-			Username: "api",
-			Password: p.AccessToken,
-		},
+	auth := &http.BasicAuth{
+		Username: "api",
+		Password: p.AccessToken,
+	}
+	remoteRefs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth: auth,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to list git branches: %w", err)
@@ -83,7 +84,6 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	if err != nil {
 		return fmt.Errorf("unable to extract github owner/repository from remote origin: %w", err)
 	}
-	ctx := context.Background()
 	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: p.AccessToken})
 	tc := oauth2.NewClient(ctx, ts)
 	client := github.NewClient(tc)
@@ -100,7 +100,7 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 			targetBranchExists = true
 		} else if strings.HasPrefix(refName, targetBranchFindPrefix) && strings.Contains(refName, targetBranchGroupHash) {
 			existingBranches = append(existingBranches, refName)
-			pullRequests, res, err := client.PullRequests.List(context.Background(), *ownerName, *repoName, &github.PullRequestListOptions{
+			pullRequests, res, err := client.PullRequests.List(ctx, *ownerName, *repoName, &github.PullRequestListOptions{
 				State: "open",
 				Head:  fmt.Sprintf("%s:%s", *ownerName, strings.TrimPrefix(refName, "refs/heads/")),
 			})
@@ -125,28 +125,38 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	if err != nil {
 		return fmt.Errorf("unable to create target branch: %w", err)
 	}
+	pushed := false
+	requested := false
+	defer func() {
+		if err != nil && !requested {
+			rollbackRequestBranch(repo, worktree, baseBranch.Name(), targetBranch, auth, pushed)
+			return
+		}
+		checkoutErr := worktree.Checkout(&git.CheckoutOptions{Branch: baseBranch.Name()})
+		if checkoutErr != nil && err == nil {
+			err = fmt.Errorf("unable to checkout to base branch: %w", checkoutErr)
+		}
+	}()
 
 	message, fullMessage := changeSet.Message()
 	_, err = applyChangesAsCommit(*worktree, dir, changeSet, changeSet.Title()+"\n\n"+message, p.Author, callbacks...)
 	if err != nil {
 		return fmt.Errorf("unable to commit changes: %w", err)
 	}
-	err = repo.Push(&git.PushOptions{
-		Auth: &http.BasicAuth{
-			Username: "api",
-			Password: p.AccessToken,
-		},
+	err = repo.PushContext(ctx, &git.PushOptions{
+		Auth: auth,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to push changes: %w", err)
 	}
+	pushed = true
 
 	LogDebug("Creating pull request for branch %s to github repository %s/%s", targetBranch.Short(), *ownerName, *repoName)
 	pullRequestBase := string(baseBranch.Name())
 	pullRequestHead := string(targetBranch)
 	pullRequestTitle := changeSet.Title()
 	pullRequestBody := fullMessage
-	pullRequest, res, err := client.PullRequests.Create(context.Background(), *ownerName, *repoName, &github.NewPullRequest{
+	pullRequest, res, err := client.PullRequests.Create(ctx, *ownerName, *repoName, &github.NewPullRequest{
 		Title: &pullRequestTitle,
 		Base:  &pullRequestBase,
 		Head:  &pullRequestHead,
@@ -156,11 +166,12 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 		return fmt.Errorf("unable to create github pull request: %w", err)
 	}
 	defer res.Body.Close()
+	requested = true
 
 	inheritedLabels := p.ExtractInheritedLabels(existingPullRequests)
 	if len(inheritedLabels) > 0 {
 		LogDebug("Adding labels for pull request %d to github repository %s/%s", *pullRequest.Number, *ownerName, *repoName)
-		_, res, err = client.Issues.AddLabelsToIssue(context.Background(), *ownerName, *repoName, *pullRequest.Number, inheritedLabels)
+		_, res, err = client.Issues.AddLabelsToIssue(ctx, *ownerName, *repoName, *pullRequest.Number, inheritedLabels)
 		if err != nil {
 			return fmt.Errorf("unable to add github pull request labels: %w", err)
 		}
@@ -170,7 +181,7 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	for _, existingPullRequest := range existingPullRequests {
 		LogDebug("Commenting on superseded pull request %d to github repository %s/%s", *existingPullRequest.Number, *ownerName, *repoName)
 		body := fmt.Sprintf("Superseded by #%d", *pullRequest.Number)
-		_, res, err = client.Issues.CreateComment(context.Background(), *ownerName, *repoName, *existingPullRequest.Number, &github.IssueComment{
+		_, res, err = client.Issues.CreateComment(ctx, *ownerName, *repoName, *existingPullRequest.Number, &github.IssueComment{
 			Body: &body,
 		})
 		if err != nil {
@@ -181,11 +192,8 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 
 	for _, refName := range existingBranches {
 		LogDebug("Removing branch %s from github repository %s/%s", refName, *ownerName, *repoName)
-		err = remote.Push(&git.PushOptions{
-			Auth: &http.BasicAuth{
-				Username: "api",
-				Password: p.AccessToken,
-			},
+		err := remote.PushContext(ctx, &git.PushOptions{
+			Auth: auth,
 			RefSpecs: []config.RefSpec{
 				config.RefSpec(":" + refName),
 			},
@@ -195,11 +203,6 @@ func (p GitHubGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 		}
 	}
 
-	err = worktree.Checkout(&git.CheckoutOptions{Branch: baseBranch.Name()})
-	if err != nil {
-		return fmt.Errorf("unable to checkout to base branch: %w", err)
-	}
-
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -22,7 +23,7 @@ type GitLabGitProvider struct {
 	AssigneeIDs []int64
 }
 
-func (p GitLabGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p GitLabGitProvider) Push(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
 		return fmt.Errorf("unable to open git repository: %w", err)
@@ -37,7 +38,7 @@ func (p GitLabGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...fu
 	if err != nil {
 		return fmt.Errorf("unable to commit changes: %w", err)
 	}
-	err = repo.Push(&git.PushOptions{
+	err = repo.PushContext(ctx, &git.PushOptions{
 		Auth: &http.BasicAuth{
 			Username: "api",
 			Password: p.AccessToken,
@@ -49,7 +50,7 @@ func (p GitLabGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...fu
 	return nil
 }
 
-func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p GitLabGitProvider) Request(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) (err error) {
 	repo, err := git.PlainOpen(dir)
 	if err != nil {
 		return fmt.Errorf("unable to open git repository: %w", err)
@@ -62,11 +63,12 @@ func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	if err != nil {
 		return fmt.Errorf("unable to get git remote origin: %w", err)
 	}
-	remoteRefs, err := remote.List(&git.ListOptions{
-		Auth: &http.BasicAuth{
-			Username: "api",
-			Password: p.AccessToken,
-		},
+	auth := &http.BasicAuth{
+		Username: "api",
+		Password: p.AccessToken,
+	}
+	remoteRefs, err := remote.ListContext(ctx, &git.ListOptions{
+		Auth: auth,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to list git branches: %w", err)
@@ -109,20 +111,31 @@ func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 	if err != nil {
 		return fmt.Errorf("unable to create target branch: %w", err)
 	}
+	pushed := false
+	requested := false
+	defer func() {
+		if err != nil && !requested {
+			rollbackRequestBranch(repo, worktree, baseBranch.Name(), targetBranch, auth, pushed)
+			return
+		}
+		checkoutErr := worktree.Checkout(&git.CheckoutOptions{Branch: baseBranch.Name()})
+		if checkoutErr != nil && err == nil {
+			err = fmt.Errorf("unable to checkout to base branch: %w", checkoutErr)
+		}
+	}()
+
 	message, fullMessage := changeSet.Message()
 	_, err = applyChangesAsCommit(*worktree, dir, changeSet, changeSet.Title()+"\n\n"+message, p.Author, callbacks...)
 	if err != nil {
 		return fmt.Errorf("unable to commit changes: %w", err)
 	}
-	err = repo.Push(&git.PushOptions{
-		Auth: &http.BasicAuth{
-			Username: "api",
-			Password: p.AccessToken,
-		},
+	err = repo.PushContext(ctx, &git.PushOptions{
+		Auth: auth,
 	})
 	if err != nil {
 		return fmt.Errorf("unable to push changes: %w", err)
 	}
+	pushed = true
 
 	LogDebug("Creating pull request for branch %s to gitlab project %s", targetBranch.Short(), *projectId)
 	pullBase := string(baseBranch.Name().Short())
@@ -137,11 +150,12 @@ func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 		Description:        &pullBody,
 		RemoveSourceBranch: &removeSourceBranch,
 		AssigneeIDs:        &p.AssigneeIDs,
-	})
+	}, gitlab.WithContext(ctx))
 	if err != nil {
 		return fmt.Errorf("unable to create gitlab merge request: %w", err)
 	}
 	defer res.Body.Close()
+	requested = true
 
 	if err != nil {
 		return fmt.Errorf("unable to create github pull request: %w", err)
@@ -150,11 +164,8 @@ func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 
 	for _, refName := range existingBranches {
 		LogDebug("Removing branch %s from gitlab project %s", refName, *projectId)
-		err := remote.Push(&git.PushOptions{
-			Auth: &http.BasicAuth{
-				Username: "api",
-				Password: p.AccessToken,
-			},
+		err := remote.PushContext(ctx, &git.PushOptions{
+			Auth: auth,
 			RefSpecs: []config.RefSpec{
 				config.RefSpec(":" + refName),
 			},
@@ -164,10 +175,6 @@ func (p GitLabGitProvider) Request(dir string, changeSet ChangeSet, callbacks ..
 		}
 	}
 
-	err = worktree.Checkout(&git.CheckoutOptions{Branch: baseBranch.Name()})
-	if err != nil {
-		return fmt.Errorf("unable to checkout to base branch: %w", err)
-	}
 	return nil
 }
 
@@ -1,5 +1,7 @@
 package internal
 
+import "context"
+
 var _ GitProvider = (*LocalGitProvider)(nil)
 
 type LocalGitProvider struct {
@@ -15,7 +17,7 @@ func warnLocalGitProvider() {
 	}
 }
 
-func (p LocalGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p LocalGitProvider) Push(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error {
 	warnLocalGitProvider()
 	err := changeSet.Push(dir)
 	if err != nil {
@@ -24,9 +26,9 @@ func (p LocalGitProvider) Push(dir string, changeSet ChangeSet, callbacks ...fun
 	return runCallbacks(callbacks)
 }
 
-func (p LocalGitProvider) Request(dir string, changeSet ChangeSet, callbacks ...func() error) error {
+func (p LocalGitProvider) Request(ctx context.Context, dir string, changeSet ChangeSet, callbacks ...func() error) error {
 	warnLocalGitProvider()
-	err := p.Push(dir, changeSet)
+	err := p.Push(ctx, dir, changeSet)
 	if err != nil {
 		return err
 	}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,3 +68,38 @@ func TestApplyChangesAsCommit(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, true, status.IsClean())
 }
+
+func TestRollbackRequestBranch(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := path.Join(dir, "file")
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = os.WriteFile(file, []byte("1"), 0o664)
+	assert.NoError(t, err)
+	_, err = applyChangesAsCommit(*worktree, dir, ChangeSet{}, "commit", GitAuthor{Name: "test", Email: "test"})
+	assert.NoError(t, err)
+	baseBranch, err := repo.Head()
+	assert.NoError(t, err)
+
+	targetBranch := plumbing.ReferenceName("refs/heads/git-ops-update/test")
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: targetBranch, Create: true})
+	assert.NoError(t, err)
+	err = os.WriteFile(file, []byte("2"), 0o664)
+	assert.NoError(t, err)
+
+	rollbackRequestBranch(repo, worktree, baseBranch.Name(), targetBranch, nil, false)
+
+	head, err := repo.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, baseBranch.Name(), head.Name())
+	status, err := worktree.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, true, status.IsClean())
+	_, err = repo.Reference(targetBranch, false)
+	assert.Equal(t, plumbing.ErrReferenceNotFound, err)
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HttpClientConfig controls how registries talk to their http endpoints. The
+// zero value disables timeouts and retries.
+type HttpClientConfig struct {
+	// Timeout limits every single request attempt
+	Timeout time.Duration
+	// Retries is the number of additional attempts for requests that failed
+	// with a network error, a rate limit or a server side error
+	Retries int
+	// RetryBackoff is the wait before the first retry and doubled for each
+	// following retry, unless the response announces a Retry-After
+	RetryBackoff time.Duration
+	// handlesRateLimits leaves responses with status 429 to the caller, for
+	// apis whose rate limits are retried with their own budget
+	handlesRateLimits bool
+}
+
+var defaultHttpClientConfig = HttpClientConfig{
+	Timeout:      time.Minute,
+	Retries:      3,
+	RetryBackoff: time.Second,
+}
+
+const httpRetryMaxWait = 5 * time.Minute
+
+func (c HttpClientConfig) NewClient() *http.Client {
+	return &http.Client{
+		Transport: c.WrapTransport(http.DefaultTransport),
+	}
+}
+
+func (c HttpClientConfig) WrapTransport(transport http.RoundTripper) http.RoundTripper {
+	if c.Timeout <= 0 && c.Retries <= 0 {
+		return transport
+	}
+	return &httpRetryTransport{
+		Transport: transport,
+		Config:    c,
+	}
+}
+
+type httpRetryTransport struct {
+	Transport http.RoundTripper
+	Config    HttpClientConfig
+}
+
+func (t *httpRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		// every attempt works on its own copy, as a RoundTripper must not
+		// modify the request of the caller
+		ctx, cancel := req.Context(), context.CancelFunc(func() {})
+		if t.Config.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(req.Context(), t.Config.Timeout)
+		}
+		attemptReq := req.Clone(ctx)
+		if attempt > 0 && req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := t.Transport.RoundTrip(attemptReq)
+		wait, retry := t.retryWait(req, attempt, resp, err)
+		if !retry {
+			if resp != nil && resp.Body != nil {
+				resp.Body = &httpCancelBody{ReadCloser: resp.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			LogDebug("Request %s %s failed with status code %d, retrying in %v", req.Method, req.URL, resp.StatusCode, wait)
+		} else {
+			LogDebug("Request %s %s failed, retrying in %v: %v", req.Method, req.URL, wait, err)
+		}
+		cancel()
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (t *httpRetryTransport) retryWait(req *http.Request, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= t.Config.Retries || req.Context().Err() != nil {
+		return 0, false
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return 0, false
+	}
+	if err == nil && !httpIsRetryableStatus(resp.StatusCode) {
+		return 0, false
+	}
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests && t.Config.handlesRateLimits {
+		return 0, false
+	}
+	wait := t.Config.RetryBackoff * time.Duration(1<<attempt)
+	if resp != nil {
+		if retryAfter, ok := httpParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			wait = retryAfter
+		}
+	}
+	if wait > httpRetryMaxWait {
+		return 0, false
+	}
+	return wait, true
+}
+
+func httpIsRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// httpParseRetryAfter understands both forms of the Retry-After header,
+// delay seconds as well as a http date.
+func httpParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// httpCancelBody releases the timeout of a request attempt only after the
+// response body has been consumed.
+type httpCancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *httpCancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpClientConfigRetries(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky":
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = w.Write([]byte("ok"))
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+			_, _ = w.Write([]byte("ok"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := HttpClientConfig{Retries: 3, RetryBackoff: time.Millisecond}.NewClient()
+	resp, err := client.Get(server.URL + "/flaky")
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+	}
+
+	atomic.StoreInt32(&attempts, 0)
+	client = HttpClientConfig{Retries: 1, RetryBackoff: time.Millisecond}.NewClient()
+	resp, err = client.Get(server.URL + "/flaky")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	}
+
+	resp, err = client.Get(server.URL + "/unknown")
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+
+	client = HttpClientConfig{Timeout: 50 * time.Millisecond, Retries: 1, RetryBackoff: time.Millisecond}.NewClient()
+	_, err = client.Get(server.URL + "/slow")
+	assert.Error(t, err)
+}
+
+func TestHttpRetryTransportKeepsRequest(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if atomic.AddInt32(&attempts, 1) < 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	transport := HttpClientConfig{Retries: 1, RetryBackoff: time.Millisecond}.WrapTransport(http.DefaultTransport)
+	req, err := http.NewRequest("POST", server.URL, strings.NewReader("payload"))
+	assert.NoError(t, err)
+	body := req.Body
+	resp, err := transport.RoundTrip(req)
+	if assert.NoError(t, err) {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, "payload", string(respBody))
+		assert.Equal(t, body, req.Body)
+	}
+}
+
+func TestHttpRetryTransportHandlesRateLimits(t *testing.T) {
+	attempts := int32(0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := HttpClientConfig{Retries: 2, RetryBackoff: time.Millisecond, handlesRateLimits: true}.NewClient()
+	resp, err := client.Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+	}
+}
+
+func TestHttpParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	wait, ok := httpParseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, wait)
+	wait, ok = httpParseRetryAfter("Fri, 01 Jan 2021 00:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, wait)
+	_, ok = httpParseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = httpParseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
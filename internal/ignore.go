@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+)
+
+// IgnoreRule excludes versions of matching resources from updates, for
+// example because they are known to be broken.
+type IgnoreRule struct {
+	// Registry is the name of the registry, empty matches all registries
+	Registry string
+	// Resource is a glob for the resource name, empty matches all resources
+	Resource string
+	Versions []VersionPattern
+	// Expires marks the rule as stale after the given time, so that it can
+	// be revisited. Stale rules still apply.
+	Expires time.Time
+}
+
+func (r IgnoreRule) Matches(registryName string, resourceName string) bool {
+	if r.Registry != "" && r.Registry != registryName {
+		return false
+	}
+	if r.Resource != "" {
+		matches, err := path.Match(r.Resource, resourceName)
+		if err != nil || !matches {
+			return false
+		}
+	}
+	return true
+}
+
+func (r IgnoreRule) IsStale(now time.Time) bool {
+	return !r.Expires.IsZero() && now.After(r.Expires)
+}
+
+// VersionPattern matches versions either by glob, by regexp (prefixed with
+// "regexp:") or by semver range (prefixed with "semver:").
+type VersionPattern struct {
+	Glob       string
+	Regexp     *regexp.Regexp
+	Constraint *SemverConstraint
+}
+
+func ParseVersionPattern(str string) (*VersionPattern, error) {
+	if strings.HasPrefix(str, "regexp:") {
+		pattern, err := regexp.Compile(strings.TrimPrefix(str, "regexp:"))
+		if err != nil {
+			return nil, fmt.Errorf("version pattern %s is invalid: %w", str, err)
+		}
+		return &VersionPattern{Regexp: pattern}, nil
+	}
+	if strings.HasPrefix(str, "semver:") {
+		constraint, err := ParseSemverConstraint(strings.TrimPrefix(str, "semver:"))
+		if err != nil {
+			return nil, fmt.Errorf("version pattern %s is invalid: %w", str, err)
+		}
+		return &VersionPattern{Constraint: constraint}, nil
+	}
+	if str == "" {
+		return nil, fmt.Errorf("version pattern must not be empty")
+	}
+	if _, err := path.Match(str, ""); err != nil {
+		return nil, fmt.Errorf("version pattern %s is invalid: %w", str, err)
+	}
+	return &VersionPattern{Glob: str}, nil
+}
+
+func (p VersionPattern) Matches(version string) bool {
+	if p.Regexp != nil {
+		return p.Regexp.MatchString(version)
+	}
+	if p.Constraint != nil {
+		v, err := semver.ParseTolerant(version)
+		return err == nil && p.Constraint.Check(v)
+	}
+	matches, err := path.Match(p.Glob, version)
+	return err == nil && matches
+}
+
+// filterIgnoredVersions drops all versions that are matched by one of the
+// rules for the resource or by one of the extra patterns.
+func filterIgnoredVersions(rules []IgnoreRule, patterns []VersionPattern, registryName string, resourceName string, versions []string) []string {
+	for _, rule := range rules {
+		if rule.Matches(registryName, resourceName) {
+			patterns = append(append([]VersionPattern{}, patterns...), rule.Versions...)
+		}
+	}
+	if len(patterns) == 0 {
+		return versions
+	}
+
+	result := []string{}
+	for _, version := range versions {
+		ignored := false
+		for _, pattern := range patterns {
+			if pattern.Matches(version) {
+				ignored = true
+				break
+			}
+		}
+		if ignored {
+			LogDebug("Ignoring version %s of %s/%s", version, registryName, resourceName)
+			continue
+		}
+		result = append(result, version)
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionPattern(t *testing.T) {
+	testCases := []struct {
+		pattern string
+		matches []string
+		misses  []string
+	}{
+		{"1.25.0", []string{"1.25.0"}, []string{"1.25.1", "v1.25.0"}},
+		{"1.25.*", []string{"1.25.0", "1.25.1-alpine"}, []string{"1.26.0"}},
+		{"regexp:^1\\.26\\.", []string{"1.26.0", "1.26.1"}, []string{"1.25.26.1"}},
+		{"semver:>=1.27 <1.27.3", []string{"1.27.0", "v1.27.2"}, []string{"1.27.3", "1.26.0", "latest"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.pattern, func(t *testing.T) {
+			pattern, err := ParseVersionPattern(testCase.pattern)
+			if assert.NoError(t, err) {
+				for _, v := range testCase.matches {
+					assert.True(t, pattern.Matches(v), v)
+				}
+				for _, v := range testCase.misses {
+					assert.False(t, pattern.Matches(v), v)
+				}
+			}
+		})
+	}
+
+	for _, pattern := range []string{"", "[", "regexp:(", "semver:>=abc"} {
+		_, err := ParseVersionPattern(pattern)
+		assert.Error(t, err, pattern)
+	}
+}
+
+func TestIgnoreRule(t *testing.T) {
+	rule := IgnoreRule{Registry: "docker", Resource: "library/*"}
+	assert.True(t, rule.Matches("docker", "library/nginx"))
+	assert.False(t, rule.Matches("docker", "bitnami/nginx"))
+	assert.False(t, rule.Matches("helm", "library/nginx"))
+	assert.True(t, IgnoreRule{}.Matches("helm", "any"))
+
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.False(t, IgnoreRule{}.IsStale(now))
+	assert.False(t, IgnoreRule{Expires: now.Add(time.Hour)}.IsStale(now))
+	assert.True(t, IgnoreRule{Expires: now.Add(-time.Hour)}.IsStale(now))
+}
+
+func TestFilterIgnoredVersions(t *testing.T) {
+	rules := []IgnoreRule{
+		{Registry: "docker", Resource: "library/nginx", Versions: []VersionPattern{{Glob: "1.25.0"}}},
+		{Resource: "library/*", Versions: []VersionPattern{{Glob: "*-rc*"}}},
+		{Registry: "helm", Versions: []VersionPattern{{Glob: "1.26.0"}}},
+	}
+	versions := []string{"1.24.0", "1.25.0", "1.26.0", "1.27.0-rc.1", "1.27.0"}
+	assert.Equal(t, []string{"1.24.0", "1.26.0", "1.27.0"}, filterIgnoredVersions(rules, nil, "docker", "library/nginx", versions))
+	assert.Equal(t, []string{"1.24.0", "1.25.0", "1.27.0-rc.1"}, filterIgnoredVersions(rules, []VersionPattern{{Glob: "1.27.0"}}, "helm", "chart", versions))
+	assert.Equal(t, versions, filterIgnoredVersions(rules, nil, "docker", "other", versions))
+}
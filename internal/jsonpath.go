@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonPathSegment struct {
+	Key      string
+	Index    int
+	IsIndex  bool
+	Wildcard bool
+}
+
+// jsonPath supports a subset of JSONPath: member access (.key, ['key']),
+// array indices ([0], [-1]), wildcards (.*, [*]) and a trailing ~ after a
+// wildcard to select property names instead of values (like $.releases.*~).
+type jsonPath struct {
+	Segments []jsonPathSegment
+	Keys     bool
+}
+
+func parseJsonPath(expr string) (*jsonPath, error) {
+	result := jsonPath{Segments: []jsonPathSegment{}}
+	i := 0
+	if strings.HasPrefix(expr, "$") {
+		i = 1
+	} else if expr != "" && expr[0] != '.' && expr[0] != '[' {
+		expr = "." + expr
+	}
+	for i < len(expr) {
+		switch expr[i] {
+		case '.':
+			i = i + 1
+			if i < len(expr) && expr[i] == '*' {
+				result.Segments = append(result.Segments, jsonPathSegment{Wildcard: true})
+				i = i + 1
+				continue
+			}
+			start := i
+			for i < len(expr) && expr[i] != '.' && expr[i] != '[' && expr[i] != '~' {
+				i = i + 1
+			}
+			if start == i {
+				return nil, fmt.Errorf("json path %s has an empty member name at position %d", expr, start)
+			}
+			result.Segments = append(result.Segments, jsonPathSegment{Key: expr[start:i]})
+		case '[':
+			end := strings.Index(expr[i:], "]")
+			if end < 0 {
+				return nil, fmt.Errorf("json path %s has an unclosed bracket at position %d", expr, i)
+			}
+			inner := strings.TrimSpace(expr[i+1 : i+end])
+			if inner == "*" {
+				result.Segments = append(result.Segments, jsonPathSegment{Wildcard: true})
+			} else if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				result.Segments = append(result.Segments, jsonPathSegment{Key: inner[1 : len(inner)-1]})
+			} else if index, err := strconv.Atoi(inner); err == nil {
+				result.Segments = append(result.Segments, jsonPathSegment{Index: index, IsIndex: true})
+			} else {
+				return nil, fmt.Errorf("json path %s has an invalid subscript [%s]", expr, inner)
+			}
+			i = i + end + 1
+		case '~':
+			if i != len(expr)-1 || len(result.Segments) == 0 || !result.Segments[len(result.Segments)-1].Wildcard {
+				return nil, fmt.Errorf("json path %s may only use ~ directly after a trailing wildcard", expr)
+			}
+			result.Keys = true
+			i = i + 1
+		default:
+			return nil, fmt.Errorf("json path %s has an unexpected character %q at position %d", expr, expr[i], i)
+		}
+	}
+	return &result, nil
+}
+
+func (p jsonPath) Evaluate(data interface{}) []interface{} {
+	current := []interface{}{data}
+	for si, s := range p.Segments {
+		keys := p.Keys && si == len(p.Segments)-1
+		next := []interface{}{}
+		for _, c := range current {
+			switch v := c.(type) {
+			case map[string]interface{}:
+				if s.Wildcard {
+					names := []string{}
+					for k := range v {
+						names = append(names, k)
+					}
+					sort.Strings(names)
+					for _, k := range names {
+						if keys {
+							next = append(next, k)
+						} else {
+							next = append(next, v[k])
+						}
+					}
+				} else if !s.IsIndex {
+					if e, ok := v[s.Key]; ok {
+						next = append(next, e)
+					}
+				}
+			case []interface{}:
+				if s.Wildcard {
+					for i, e := range v {
+						if keys {
+							next = append(next, strconv.Itoa(i))
+						} else {
+							next = append(next, e)
+						}
+					}
+				} else if s.IsIndex {
+					index := s.Index
+					if index < 0 {
+						index = len(v) + index
+					}
+					if index >= 0 && index < len(v) {
+						next = append(next, v[index])
+					}
+				}
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func (p jsonPath) EvaluateStrings(data interface{}) ([]string, error) {
+	result := []string{}
+	for _, v := range p.Evaluate(data) {
+		switch v2 := v.(type) {
+		case string:
+			result = append(result, v2)
+		case json.Number:
+			result = append(result, v2.String())
+		default:
+			return nil, fmt.Errorf("json path matched a value of type %T, but only strings and numbers are supported", v)
+		}
+	}
+	return result, nil
+}
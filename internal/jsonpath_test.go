@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJsonPath(t *testing.T) {
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(`{
+		"latest": "1.2.0",
+		"releases": [{"version": "1.0.0"}, {"version": "1.1.0"}, {"version": 1.10}],
+		"files": {"1.0.0": [], "1.1.0": []},
+		"odd key": ["a"]
+	}`)))
+	decoder.UseNumber()
+	assert.NoError(t, decoder.Decode(&data))
+
+	testCases := []struct {
+		expr     string
+		expected []string
+	}{
+		{expr: "$.latest", expected: []string{"1.2.0"}},
+		{expr: "latest", expected: []string{"1.2.0"}},
+		{expr: ".latest", expected: []string{"1.2.0"}},
+		{expr: "$.releases[*].version", expected: []string{"1.0.0", "1.1.0", "1.10"}},
+		{expr: "$.releases.*.version", expected: []string{"1.0.0", "1.1.0", "1.10"}},
+		{expr: "$.releases[0].version", expected: []string{"1.0.0"}},
+		{expr: "$.releases[-1].version", expected: []string{"1.10"}},
+		{expr: "$.releases[5].version", expected: []string{}},
+		{expr: "$.files.*~", expected: []string{"1.0.0", "1.1.0"}},
+		{expr: "$['odd key'][*]", expected: []string{"a"}},
+		{expr: "$.unknown[*]", expected: []string{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			path, err := parseJsonPath(tc.expr)
+			if assert.NoError(t, err) {
+				actual, err := path.EvaluateStrings(data)
+				if assert.NoError(t, err) {
+					assert.Equal(t, tc.expected, actual)
+				}
+			}
+		})
+	}
+
+	path, err := parseJsonPath("$.releases[*]")
+	if assert.NoError(t, err) {
+		_, err = path.EvaluateStrings(data)
+		assert.Error(t, err)
+	}
+
+	for _, expr := range []string{"$.", "$[", "$[x]", "$.files~", "$.*~.a", "$x"} {
+		_, err := parseJsonPath(expr)
+		assert.Error(t, err, expr)
+	}
+}
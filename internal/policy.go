@@ -6,6 +6,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/blang/semver/v4"
 )
@@ -13,8 +14,21 @@ import (
 type Policy struct {
 	Pattern  *regexp.Regexp
 	Extracts []Extract
+	// MinAge skips versions that have been published more recently, as well
+	// as versions whose publish time is unknown
+	MinAge time.Duration
+	// Step limits updates to the next minor or major version instead of the
+	// newest one, see PolicyStepMinor and PolicyStepMajor
+	Step string
 }
 
+const (
+	// PolicyStepMinor updates to the latest version of the next minor version
+	PolicyStepMinor = "minor"
+	// PolicyStepMajor updates to the latest version of the next major version
+	PolicyStepMajor = "major"
+)
+
 // Extract
 type Extract struct {
 	Key      string
@@ -29,6 +43,13 @@ type ExtractStrategy interface {
 	Segments(v string) map[string]string
 }
 
+// ConstrainedExtractStrategy is implemented by strategies that can restrict
+// versions to an absolute range, independent of the current version.
+type ConstrainedExtractStrategy interface {
+	ExtractStrategy
+	CheckConstraint(v string) error
+}
+
 var _ ExtractStrategy = (*LexicographicExtractStrategy)(nil)
 
 type LexicographicExtractStrategy struct {
@@ -41,7 +62,7 @@ type NumericExtractStrategy struct {
 	Pin bool
 }
 
-var _ ExtractStrategy = (*SemverExtractStrategy)(nil)
+var _ ConstrainedExtractStrategy = (*SemverExtractStrategy)(nil)
 
 type SemverExtractStrategy struct {
 	Relaxed          bool
@@ -49,6 +70,7 @@ type SemverExtractStrategy struct {
 	PinMinor         bool
 	PinPatch         bool
 	AllowPrereleases bool
+	Constraint       *SemverConstraint
 }
 
 var extractPattern = regexp.MustCompile(`<([^>]+)>`)
@@ -135,7 +157,7 @@ func (l versionParsedList) Less(i, j int) bool {
 	return false
 }
 
-func (p Policy) FilterAndSort(currentVersion string, availableVersions []string, prefix string, suffix string, filter map[string]interface{}) ([]string, error) {
+func (p Policy) FilterAndSort(currentVersion string, availableVersions []string, metadata map[string]VersionMetadata, prefix string, suffix string, filter map[string]interface{}) ([]string, error) {
 	_, currentVersionParsed, err := p.Parse(currentVersion, prefix, suffix)
 	if err != nil {
 		return nil, err
@@ -143,11 +165,23 @@ func (p Policy) FilterAndSort(currentVersion string, availableVersions []string,
 	if currentVersionParsed == nil {
 		return nil, fmt.Errorf("version %s does not match pattern %v with prefix \"%s\" and suffix \"%s\"", currentVersion, p.Pattern, prefix, suffix)
 	}
+	for i, e := range p.Extracts {
+		if strategy, ok := e.Strategy.(ConstrainedExtractStrategy); ok {
+			if err := strategy.CheckConstraint(currentVersionParsed[i]); err != nil {
+				return nil, fmt.Errorf("current version %s: %w", currentVersion, err)
+			}
+		}
+	}
 
 	temp1 := []versionParsed{}
 	for _, version := range availableVersions {
 		segments, parsed, err := p.Parse(version, prefix, suffix)
 		if parsed != nil && err == nil {
+			if m, ok := metadata[version]; ok {
+				for k, v := range m.Segments() {
+					segments["registry."+k] = v
+				}
+			}
 			matchesFilter := true
 			for k, v := range filter {
 				vString, ok := v.(string)
@@ -216,19 +250,132 @@ func (p Policy) FilterAndSort(currentVersion string, availableVersions []string,
 	return result, nil
 }
 
-func (p Policy) FindNext(currentVersion string, availableVersions []string, prefix string, suffix string, filter map[string]interface{}) (*string, error) {
+func (p Policy) FindNext(currentVersion string, availableVersions []string, metadata map[string]VersionMetadata, prefix string, suffix string, filter map[string]interface{}) (*string, error) {
 	allVersions := append(availableVersions, currentVersion)
-	allFilteredSortedVersions, err := p.FilterAndSort(currentVersion, allVersions, prefix, suffix, filter)
+	allFilteredSortedVersions, err := p.FilterAndSort(currentVersion, allVersions, metadata, prefix, suffix, filter)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	newerVersions := []string{}
+	for _, nextVersion := range allFilteredSortedVersions {
+		if nextVersion != currentVersion && !p.IsOldEnough(metadata[nextVersion], now) {
+			continue
+		}
+		if p.Compare(currentVersion, nextVersion, prefix, suffix) >= 0 {
+			break
+		}
+		newerVersions = append(newerVersions, nextVersion)
+	}
+	if len(newerVersions) == 0 {
+		return &currentVersion, nil
+	}
+	if p.Step != "" {
+		return p.findNextStep(currentVersion, newerVersions, prefix, suffix)
+	}
+	return &newerVersions[0], nil
+}
+
+// findNextStep picks the best of the newer versions (sorted best first) that
+// belongs to the closest minor or major version after the current one. If
+// there is none, the best version of the current minor or major is taken.
+func (p Policy) findNextStep(currentVersion string, newerVersions []string, prefix string, suffix string) (*string, error) {
+	currentStep, err := p.stepOf(currentVersion, prefix, suffix)
+	if err != nil {
+		return nil, err
+	}
+	var result *string
+	var resultStep []int
+	for i, version := range newerVersions {
+		step, err := p.stepOf(version, prefix, suffix)
+		if err != nil {
+			return nil, err
+		}
+		if compareSteps(step, currentStep) <= 0 {
+			continue
+		}
+		if result == nil || compareSteps(step, resultStep) < 0 {
+			result = &newerVersions[i]
+			resultStep = step
+		}
+	}
+	if result == nil {
+		return &newerVersions[0], nil
+	}
+	return result, nil
+}
+
+// stepOf returns the major (and minor) version number according to the
+// segments of the first extract that provides them.
+func (p Policy) stepOf(version string, prefix string, suffix string) ([]int, error) {
+	_, parsed, err := p.Parse(version, prefix, suffix)
 	if err != nil {
 		return nil, err
 	}
-	if len(allFilteredSortedVersions) > 0 {
-		nextVersion := (allFilteredSortedVersions)[0]
-		if p.Compare(currentVersion, nextVersion, prefix, suffix) < 0 {
-			return &allFilteredSortedVersions[0], nil
+	if parsed == nil {
+		return nil, fmt.Errorf("version %s does not match prefix \"%s\" and suffix \"%s\"", version, prefix, suffix)
+	}
+	keys := []string{"major"}
+	if p.Step == PolicyStepMinor {
+		keys = append(keys, "minor")
+	}
+	for i, e := range p.Extracts {
+		segments := e.Strategy.Segments(parsed[i])
+		result := []int{}
+		for _, key := range keys {
+			n, err := strconv.Atoi(segments[key])
+			if err != nil {
+				break
+			}
+			result = append(result, n)
+		}
+		if len(result) == len(keys) {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("version %s has no extract with %s segments to step by", version, strings.Join(keys, "/"))
+}
+
+func compareSteps(s1 []int, s2 []int) int {
+	for i := range s1 {
+		if s1[i] != s2[i] {
+			if s1[i] < s2[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (p Policy) IsOldEnough(metadata VersionMetadata, now time.Time) bool {
+	if p.MinAge <= 0 {
+		return true
+	}
+	if metadata.Published.IsZero() {
+		return false
+	}
+	return !metadata.Published.Add(p.MinAge).After(now)
+}
+
+// WithSemverConstraint returns a copy of the policy with the constraint of
+// all semver extracts replaced.
+func (p Policy) WithSemverConstraint(constraint *SemverConstraint) (Policy, error) {
+	extracts := []Extract{}
+	replaced := false
+	for _, e := range p.Extracts {
+		if strategy, ok := e.Strategy.(SemverExtractStrategy); ok {
+			strategy.Constraint = constraint
+			e.Strategy = strategy
+			replaced = true
 		}
+		extracts = append(extracts, e)
+	}
+	if !replaced {
+		return Policy{}, fmt.Errorf("policy has no semver extract to apply constraint %s to", constraint)
 	}
-	return &currentVersion, nil
+	p.Extracts = extracts
+	return p, nil
 }
 
 func (p Policy) Compare(v1 string, v2 string, prefix string, suffix string) int {
@@ -372,9 +519,30 @@ func (str SemverExtractStrategy) IsCompatible(v1 string, v2 string) bool {
 	if !str.AllowPrereleases && len(v2sv.Pre) > 0 {
 		return false
 	}
+	if str.Constraint != nil && !str.Constraint.Check(v2sv) {
+		return false
+	}
 	return true
 }
 
+func (str SemverExtractStrategy) CheckConstraint(v string) error {
+	if str.Constraint == nil {
+		return nil
+	}
+	if str.Relaxed {
+		v = str.fillMissingZeros(v)
+	}
+	vsv, err := semver.Make(v)
+	if err != nil {
+		// invalid versions are reported by IsValid
+		return nil
+	}
+	if !str.Constraint.Check(vsv) {
+		return fmt.Errorf("%s is outside of constraint %s", v, str.Constraint)
+	}
+	return nil
+}
+
 func (str SemverExtractStrategy) Segments(v string) map[string]string {
 	if str.Relaxed {
 		v = str.fillMissingZeros(v)
@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var _ ExtractStrategy = (*CalverExtractStrategy)(nil)
+
+// CalverExtractStrategy handles calendar versions like 2024.03.1, 24.04 or
+// 20240315. Pinning a component also pins all components before it in the
+// layout.
+type CalverExtractStrategy struct {
+	Layout   CalverLayout
+	PinYear  bool
+	PinMonth bool
+	PinWeek  bool
+	PinDay   bool
+	PinMajor bool
+	PinMinor bool
+}
+
+// CalverLayout describes the format of calendar versions with the tokens
+// from https://calver.org, for example YYYY.0M.MICRO. The short tokens MM, WW
+// and DD accept zero padded values as well, so YYYYMMDD matches 20240315.
+type CalverLayout struct {
+	Raw        string
+	pattern    *regexp.Regexp
+	components []string
+}
+
+var calverTokens = []struct {
+	token     string
+	component string
+	pattern   string
+}{
+	{"YYYY", "year", `\d{4}`},
+	{"YY", "year", `[1-9]\d{0,2}|0`},
+	{"0Y", "year", `\d{2,3}`},
+	{"MM", "month", `0?[1-9]|1[0-2]`},
+	{"0M", "month", `0[1-9]|1[0-2]`},
+	{"WW", "week", `0?[1-9]|[1-4]\d|5[0-3]`},
+	{"0W", "week", `0[1-9]|[1-4]\d|5[0-3]`},
+	{"DD", "day", `0?[1-9]|[12]\d|3[01]`},
+	{"0D", "day", `0[1-9]|[12]\d|3[01]`},
+	{"MAJOR", "major", `\d+`},
+	{"MINOR", "minor", `\d+`},
+	{"MICRO", "micro", `\d+`},
+}
+
+func ParseCalverLayout(layout string) (*CalverLayout, error) {
+	pattern := "^"
+	components := []string{}
+	rest := layout
+	for rest != "" {
+		found := false
+		for _, t := range calverTokens {
+			if strings.HasPrefix(rest, t.token) {
+				for _, c := range components {
+					if c == t.component {
+						return nil, fmt.Errorf("calver layout %s contains %s more than once", layout, t.component)
+					}
+				}
+				pattern += "(" + t.pattern + ")"
+				components = append(components, t.component)
+				rest = strings.TrimPrefix(rest, t.token)
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if rest[0] >= 'A' && rest[0] <= 'Z' || rest[0] >= '0' && rest[0] <= '9' {
+			return nil, fmt.Errorf("calver layout %s has unknown token at %s", layout, rest)
+		}
+		pattern += regexp.QuoteMeta(rest[:1])
+		rest = rest[1:]
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("calver layout %s contains no tokens", layout)
+	}
+	return &CalverLayout{
+		Raw:        layout,
+		pattern:    regexp.MustCompile(pattern + "$"),
+		components: components,
+	}, nil
+}
+
+// parse returns the numbers of all components in layout order, with years
+// always expanded to four digits.
+func (l CalverLayout) parse(v string) ([]int, bool) {
+	if l.pattern == nil {
+		return nil, false
+	}
+	match := l.pattern.FindStringSubmatch(v)
+	if match == nil {
+		return nil, false
+	}
+	result := []int{}
+	values := map[string]int{}
+	for i, c := range l.components {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return nil, false
+		}
+		if c == "year" && len(match[i+1]) < 4 {
+			n = n + 2000
+		}
+		result = append(result, n)
+		values[c] = n
+	}
+	year, hasYear := values["year"]
+	month, hasMonth := values["month"]
+	day, hasDay := values["day"]
+	if hasYear && hasMonth && hasDay {
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Month() != time.Month(month) {
+			return nil, false
+		}
+	}
+	return result, true
+}
+
+func (l CalverLayout) String() string {
+	return l.Raw
+}
+
+func (str CalverExtractStrategy) IsValid(v string) bool {
+	_, ok := str.Layout.parse(v)
+	return ok
+}
+
+func (str CalverExtractStrategy) Compare(v1 string, v2 string) int {
+	p1, ok1 := str.Layout.parse(v1)
+	p2, ok2 := str.Layout.parse(v2)
+	if !ok1 || !ok2 {
+		return 0
+	}
+	for i := range p1 {
+		if p1[i] != p2[i] {
+			if p1[i] < p2[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func (str CalverExtractStrategy) IsCompatible(v1 string, v2 string) bool {
+	p1, ok1 := str.Layout.parse(v1)
+	p2, ok2 := str.Layout.parse(v2)
+	if !ok1 || !ok2 {
+		return false
+	}
+	pinned := -1
+	for i, c := range str.Layout.components {
+		if c == "year" && str.PinYear || c == "month" && str.PinMonth || c == "week" && str.PinWeek ||
+			c == "day" && str.PinDay || c == "major" && str.PinMajor || c == "minor" && str.PinMinor {
+			pinned = i
+		}
+	}
+	for i := 0; i <= pinned; i++ {
+		if p1[i] != p2[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (str CalverExtractStrategy) Segments(v string) map[string]string {
+	parsed, ok := str.Layout.parse(v)
+	if !ok {
+		return map[string]string{}
+	}
+	result := map[string]string{}
+	for i, c := range str.Layout.components {
+		result[c] = strconv.Itoa(parsed[i])
+	}
+	return result
+}
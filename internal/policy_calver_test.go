@@ -0,0 +1,119 @@
+package internal
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustParseCalverLayout(t *testing.T, layout string) CalverLayout {
+	l, err := ParseCalverLayout(layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *l
+}
+
+func TestParseCalverLayout(t *testing.T) {
+	for _, layout := range []string{"YYYY.0M.MICRO", "YY.MM", "YYYYMMDD", "YYYY.0W", "0Y.0D-MAJOR", "v.YYYY.MINOR"} {
+		_, err := ParseCalverLayout(layout)
+		assert.NoError(t, err, layout)
+	}
+	for _, layout := range []string{"", "...", "YYYY.YY", "YYYY.MODIFIER", "YYYY.1"} {
+		_, err := ParseCalverLayout(layout)
+		assert.Error(t, err, layout)
+	}
+}
+
+func TestCalverSortStrategyIsValid(t *testing.T) {
+	str := CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YYYY.0M.MICRO")}
+	assert.Equal(t, true, str.IsValid("2024.03.1"))
+	assert.Equal(t, true, str.IsValid("2024.12.0"))
+	assert.Equal(t, false, str.IsValid("2024.3.1"))
+	assert.Equal(t, false, str.IsValid("2024.13.1"))
+	assert.Equal(t, false, str.IsValid("24.03.1"))
+	assert.Equal(t, false, str.IsValid("2024.03"))
+	assert.Equal(t, false, str.IsValid(""))
+
+	str2 := CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YY.MM")}
+	assert.Equal(t, true, str2.IsValid("24.4"))
+	assert.Equal(t, true, str2.IsValid("24.04"))
+	assert.Equal(t, true, str2.IsValid("24.10"))
+	assert.Equal(t, false, str2.IsValid("24.0"))
+	assert.Equal(t, false, str2.IsValid("024.04"))
+
+	str3 := CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YYYYMMDD")}
+	assert.Equal(t, true, str3.IsValid("20240315"))
+	assert.Equal(t, true, str3.IsValid("20241231"))
+	assert.Equal(t, true, str3.IsValid("20240229"))
+	assert.Equal(t, false, str3.IsValid("20230229"))
+	assert.Equal(t, false, str3.IsValid("20240431"))
+	assert.Equal(t, false, str3.IsValid("20241301"))
+}
+
+func TestCalverSortStrategyCompare(t *testing.T) {
+	str := CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YY.0M.MICRO")}
+	assert.Equal(t, 0, str.Compare("24.04.1", "24.04.1"))
+	assert.Equal(t, -1, str.Compare("24.04.1", "24.04.2"))
+	assert.Equal(t, -1, str.Compare("24.04.9", "24.10.0"))
+	assert.Equal(t, -1, str.Compare("99.12.9", "100.01.0"))
+	assert.Equal(t, 1, str.Compare("25.01.0", "24.10.3"))
+	assert.Equal(t, 0, str.Compare("24.04.1", "invalid"))
+
+	str2 := CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YYYYMMDD")}
+	assert.Equal(t, -1, str2.Compare("20240315", "20241105"))
+}
+
+func TestCalverSortStrategyIsCompatible(t *testing.T) {
+	layout := mustParseCalverLayout(t, "YYYY.0M.MICRO")
+	assert.Equal(t, true, CalverExtractStrategy{Layout: layout}.IsCompatible("2024.03.1", "2025.01.0"))
+	assert.Equal(t, false, CalverExtractStrategy{Layout: layout}.IsCompatible("2024.03.1", "invalid"))
+	assert.Equal(t, true, CalverExtractStrategy{Layout: layout, PinYear: true}.IsCompatible("2024.03.1", "2024.11.0"))
+	assert.Equal(t, false, CalverExtractStrategy{Layout: layout, PinYear: true}.IsCompatible("2024.03.1", "2025.03.1"))
+	assert.Equal(t, true, CalverExtractStrategy{Layout: layout, PinMonth: true}.IsCompatible("2024.03.1", "2024.03.4"))
+	assert.Equal(t, false, CalverExtractStrategy{Layout: layout, PinMonth: true}.IsCompatible("2024.03.1", "2024.04.1"))
+	assert.Equal(t, false, CalverExtractStrategy{Layout: layout, PinMonth: true}.IsCompatible("2024.03.1", "2025.03.1"))
+	assert.Equal(t, true, CalverExtractStrategy{Layout: layout, PinMajor: true}.IsCompatible("2024.03.1", "2025.03.1"))
+}
+
+func TestCalverSegments(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"year":  "2024",
+		"month": "3",
+		"micro": "1",
+	}, CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YY.0M.MICRO")}.Segments("24.03.1"))
+
+	assert.Equal(t, map[string]string{
+		"year":  "2024",
+		"month": "3",
+		"day":   "15",
+	}, CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YYYYMMDD")}.Segments("20240315"))
+
+	assert.Equal(t, map[string]string{}, CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YYYYMMDD")}.Segments("invalid"))
+}
+
+func TestCalverPolicyFindNext(t *testing.T) {
+	p := Policy{
+		Pattern: regexp.MustCompile(`^(?P<all>.*)$`),
+		Extracts: []Extract{
+			{
+				Key:      "calver",
+				Value:    "<all>",
+				Strategy: CalverExtractStrategy{Layout: mustParseCalverLayout(t, "YY.0M")},
+			},
+		},
+	}
+	actual, err := p.FindNext("22.04", []string{"20.04", "22.04", "22.10", "23.04", "24.04", "rolling"}, nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "24.04", *actual)
+	}
+	actual, err = p.FindNext("22.04", []string{"20.04", "22.04", "22.10", "23.04", "24.04"}, nil, "", "", map[string]interface{}{"calver.month": "4"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "24.04", *actual)
+	}
+	actual, err = p.FindNext("22.04", []string{"20.04", "22.04", "22.10", "23.04", "24.04"}, nil, "", "", map[string]interface{}{"calver.month": "10"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "22.10", *actual)
+	}
+}
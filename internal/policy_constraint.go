@@ -0,0 +1,184 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver/v4"
+)
+
+// SemverConstraint restricts semver versions to an absolute range like
+// ">=1.24 <1.28", "~2.3" or "^1.2 || ^2". Matching is left to
+// semver.ParseRange, which only understands full versions, so partial
+// versions, wildcards, tilde and caret ranges are rewritten into its syntax
+// first. Exclusive upper bounds also exclude the prereleases of the bound,
+// so "<1.28" does not match "1.28.0-rc.1".
+type SemverConstraint struct {
+	Raw string
+	// expanded is the constraint in semver.ParseRange syntax
+	expanded string
+	// matches is the range parsed from expanded. Being a function it makes
+	// constraints incomparable, so tests compare Raw instead.
+	matches semver.Range
+}
+
+func ParseSemverConstraint(str string) (*SemverConstraint, error) {
+	groups := []string{}
+	for _, groupStr := range strings.Split(str, "||") {
+		group := []string{}
+		fields := strings.Fields(strings.ReplaceAll(groupStr, ",", " "))
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// allow a space between operator and version like ">= 1.24"
+			if strings.Trim(field, "<>=!~^") == "" && i+1 < len(fields) {
+				field = field + fields[i+1]
+				i++
+			}
+			comparators, err := expandSemverComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("constraint %s is invalid: %w", str, err)
+			}
+			group = append(group, comparators...)
+		}
+		if len(group) == 0 {
+			return nil, fmt.Errorf("constraint %s is invalid: empty range", str)
+		}
+		groups = append(groups, strings.Join(group, " "))
+	}
+	expanded := strings.Join(groups, " || ")
+	matches, err := semver.ParseRange(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("constraint %s is invalid: %w", str, err)
+	}
+	return &SemverConstraint{Raw: strings.TrimSpace(str), expanded: expanded, matches: matches}, nil
+}
+
+func (c SemverConstraint) Check(v semver.Version) bool {
+	return c.matches != nil && c.matches(v)
+}
+
+func (c SemverConstraint) String() string {
+	return c.Raw
+}
+
+// expandSemverComparator rewrites a single comparator into one or two
+// comparators with full versions.
+func expandSemverComparator(str string) ([]string, error) {
+	op := ""
+	for _, prefix := range []string{">=", "<=", "!=", "==", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(str, prefix) {
+			op = prefix
+			break
+		}
+	}
+	versionStr := strings.TrimPrefix(strings.TrimPrefix(str, op), "v")
+	if versionStr == "*" || versionStr == "x" || versionStr == "X" {
+		return []string{">=0.0.0"}, nil
+	}
+	version, parts, err := parsePartialSemver(versionStr)
+	if err != nil {
+		return nil, err
+	}
+	if parts < 3 && op == "!=" {
+		return nil, fmt.Errorf("%s requires a full version", str)
+	}
+
+	switch op {
+	case "<":
+		return []string{"<" + semverExcludePrereleases(version).String()}, nil
+	case ">=", "!=":
+		return []string{op + version.String()}, nil
+	case ">":
+		if parts < 3 {
+			return []string{">=" + semverBump(version, parts).String()}, nil
+		}
+		return []string{op + version.String()}, nil
+	case "<=":
+		if parts < 3 {
+			return []string{"<" + semverExcludePrereleases(semverBump(version, parts)).String()}, nil
+		}
+		return []string{op + version.String()}, nil
+	case "~":
+		upper := semverBump(version, 2)
+		if parts == 1 {
+			upper = semverBump(version, 1)
+		}
+		return semverRange(version, upper), nil
+	case "^":
+		// bump the left-most non-zero component that has been given
+		bumpAt := parts
+		if version.Major > 0 || parts == 1 {
+			bumpAt = 1
+		} else if version.Minor > 0 || parts == 2 {
+			bumpAt = 2
+		}
+		return semverRange(version, semverBump(version, bumpAt)), nil
+	default:
+		if parts < 3 {
+			return semverRange(version, semverBump(version, parts)), nil
+		}
+		return []string{"=" + version.String()}, nil
+	}
+}
+
+// parsePartialSemver parses versions like "1", "1.24" or "1.24.x" and
+// returns how many of the major, minor and patch components were given.
+func parsePartialSemver(str string) (semver.Version, int, error) {
+	core, rest := str, ""
+	if i := strings.IndexAny(str, "-+"); i >= 0 {
+		core, rest = str[:i], str[i:]
+	}
+	segments := strings.Split(core, ".")
+	if len(segments) > 3 {
+		return semver.Version{}, 0, fmt.Errorf("%s is not a valid version", str)
+	}
+	numbers := []string{}
+	for _, segment := range segments {
+		if segment == "x" || segment == "X" || segment == "*" {
+			break
+		}
+		if _, err := strconv.ParseUint(segment, 10, 64); err != nil {
+			return semver.Version{}, 0, fmt.Errorf("%s is not a valid version", str)
+		}
+		numbers = append(numbers, segment)
+	}
+	if len(numbers) == 0 || len(numbers) < 3 && rest != "" {
+		return semver.Version{}, 0, fmt.Errorf("%s is not a valid version", str)
+	}
+	parts := len(numbers)
+	for len(numbers) < 3 {
+		numbers = append(numbers, "0")
+	}
+	version, err := semver.Make(strings.Join(numbers, ".") + rest)
+	if err != nil {
+		return semver.Version{}, 0, fmt.Errorf("%s is not a valid version: %w", str, err)
+	}
+	return version, parts, nil
+}
+
+// semverBump increments the component at the given position (1 = major,
+// 2 = minor, 3 = patch) and resets all following ones.
+func semverBump(v semver.Version, at int) semver.Version {
+	switch at {
+	case 1:
+		return semver.Version{Major: v.Major + 1}
+	case 2:
+		return semver.Version{Major: v.Major, Minor: v.Minor + 1}
+	default:
+		return semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+	}
+}
+
+// semverExcludePrereleases turns an exclusive upper bound into the lowest
+// possible prerelease of it, unless it already is a prerelease.
+func semverExcludePrereleases(v semver.Version) semver.Version {
+	if len(v.Pre) > 0 {
+		return v
+	}
+	return semver.Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Pre: []semver.PRVersion{{VersionNum: 0, IsNum: true}}}
+}
+
+func semverRange(lower semver.Version, upper semver.Version) []string {
+	return []string{">=" + lower.String(), "<" + semverExcludePrereleases(upper).String()}
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSemverConstraint(t *testing.T) {
+	testCases := []struct {
+		constraint string
+		matches    []string
+		misses     []string
+	}{
+		{">=1.24 <1.28", []string{"1.24.0", "1.27.99"}, []string{"1.23.9", "1.28.0", "1.28.0-rc.1", "1.24.0-rc.1"}},
+		{">= 1.24, < 1.28", []string{"1.24.0", "1.27.99"}, []string{"1.23.9", "1.28.0"}},
+		{">1.24", []string{"1.25.0"}, []string{"1.24.5"}},
+		{">1.24.1", []string{"1.24.2"}, []string{"1.24.1"}},
+		{"<=1.27", []string{"1.27.5"}, []string{"1.28.0", "1.28.0-rc.1"}},
+		{"<=1.27.5", []string{"1.27.5"}, []string{"1.27.6"}},
+		{"1.24", []string{"1.24.0", "1.24.9"}, []string{"1.23.0", "1.25.0"}},
+		{"=1.24.3", []string{"1.24.3"}, []string{"1.24.4"}},
+		{"1.x", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{"~2.3", []string{"2.3.0", "2.3.9"}, []string{"2.4.0"}},
+		{"~2.3.4", []string{"2.3.4", "2.3.9"}, []string{"2.3.3", "2.4.0"}},
+		{"~2", []string{"2.0.0", "2.9.0"}, []string{"3.0.0"}},
+		{"^1.2", []string{"1.2.0", "1.9.0"}, []string{"1.1.0", "2.0.0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4"}},
+		{"^0", []string{"0.9.0"}, []string{"1.0.0"}},
+		{"^1.2 || ^3", []string{"1.5.0", "3.1.0"}, []string{"2.0.0", "4.0.0"}},
+		{">=1.0.0 !=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"v1.2", []string{"1.2.0"}, []string{"1.3.0"}},
+		{"*", []string{"0.0.1", "9.0.0"}, []string{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.constraint, func(t *testing.T) {
+			constraint, err := ParseSemverConstraint(testCase.constraint)
+			if assert.NoError(t, err) {
+				for _, v := range testCase.matches {
+					assert.True(t, constraint.Check(semver.MustParse(v)), v)
+				}
+				for _, v := range testCase.misses {
+					assert.False(t, constraint.Check(semver.MustParse(v)), v)
+				}
+			}
+		})
+	}
+
+	for _, constraint := range []string{"", ">=", "1.2.3.4", "abc", ">=1.a", "!=1.2", "1.2-rc.1", "1.2 ||"} {
+		_, err := ParseSemverConstraint(constraint)
+		assert.Error(t, err, constraint)
+	}
+}
+
+func TestParseSemverConstraintExpanded(t *testing.T) {
+	constraint, err := ParseSemverConstraint(">= 1.24, <1.28 || ^2.3 || 3.x")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ">=1.24.0 <1.28.0-0 || >=2.3.0 <3.0.0-0 || >=3.0.0 <4.0.0-0", constraint.expanded)
+	}
+}
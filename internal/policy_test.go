@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -139,7 +140,7 @@ func TestPolicyParse(t *testing.T) {
 
 func TestPolicyFilterAndSort(t *testing.T) {
 	p1 := Policy{}
-	actual, err := p1.FilterAndSort("1", []string{"1", "2", "3"}, "", "", nil)
+	actual, err := p1.FilterAndSort("1", []string{"1", "2", "3"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, []string{"3", "2", "1"}, actual)
 	}
@@ -157,27 +158,27 @@ func TestPolicyFilterAndSort(t *testing.T) {
 			},
 		},
 	}
-	actual, err = p2.FilterAndSort("1.0", strings.Split("18.04 18.10 19.04 19.10 20.04 20.10 21.04 21.10 22.04", " "), "", "", nil)
+	actual, err = p2.FilterAndSort("1.0", strings.Split("18.04 18.10 19.04 19.10 20.04 20.10 21.04 21.10 22.04", " "), nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("22.04 21.10 21.04 20.10 20.04 19.10 19.04 18.10 18.04", " "), actual)
 	}
-	actual, err = p2.FilterAndSort("v1.0-ubuntu", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), "v", "-ubuntu", nil)
+	actual, err = p2.FilterAndSort("v1.0-ubuntu", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), nil, "v", "-ubuntu", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("v22.04-ubuntu v21.10-ubuntu v21.04-ubuntu v20.10-ubuntu v20.04-ubuntu v19.10-ubuntu v19.04-ubuntu v18.10-ubuntu v18.04-ubuntu", " "), actual)
 	}
-	_, err = p2.FilterAndSort("v1.0", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), "v", "-ubuntu", nil)
+	_, err = p2.FilterAndSort("v1.0", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), nil, "v", "-ubuntu", nil)
 	assert.Error(t, err)
-	_, err = p2.FilterAndSort("1.0-ubuntu", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), "v", "-ubuntu", nil)
+	_, err = p2.FilterAndSort("1.0-ubuntu", strings.Split("17.10 v18.04-ubuntu v18.10-ubuntu v19.04-ubuntu v19.10-ubuntu v20.04-ubuntu v20.10-ubuntu v21.04-ubuntu v21.10-ubuntu v22.04-ubuntu", " "), nil, "v", "-ubuntu", nil)
 	assert.Error(t, err)
-	actual, err = p2.FilterAndSort("1.0", strings.Split("1.2 1.10 2.1 2.10", " "), "", "", nil)
+	actual, err = p2.FilterAndSort("1.0", strings.Split("1.2 1.10 2.1 2.10", " "), nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.10 2.1 1.10 1.2", " "), actual)
 	}
-	actual, err = p2.FilterAndSort("1.0", strings.Split("1.10 1.2 2.10 2.1", " "), "", "", nil)
+	actual, err = p2.FilterAndSort("1.0", strings.Split("1.10 1.2 2.10 2.1", " "), nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.10 2.1 1.10 1.2", " "), actual)
 	}
-	actual, err = p2.FilterAndSort("1.0", strings.Split("2.10-a 1.10 1.2 2.10 2.1 1.10-b 1.10-c 1.10-a", " "), "", "", nil)
+	actual, err = p2.FilterAndSort("1.0", strings.Split("2.10-a 1.10 1.2 2.10 2.1 1.10-b 1.10-c 1.10-a", " "), nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.10-a 2.10 2.1 1.10-c 1.10-b 1.10-a 1.10 1.2", " "), actual)
 	}
@@ -195,7 +196,7 @@ func TestPolicyFilterAndSort(t *testing.T) {
 			},
 		},
 	}
-	actual, err = p3.FilterAndSort("1.2", strings.Split("1.0 2 1 1.1 1.2 1.3 2.0", " "), "", "", nil)
+	actual, err = p3.FilterAndSort("1.2", strings.Split("1.0 2 1 1.1 1.2 1.3 2.0", " "), nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.0 2 1.3 1.2 1.1 1.0 1", " "), actual)
 	}
@@ -213,31 +214,31 @@ func TestPolicyFilterAndSort(t *testing.T) {
 			},
 		},
 	}
-	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), "", "", map[string]interface{}{
+	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), nil, "", "", map[string]interface{}{
 		"prefix": "a",
 	})
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("a-2.0-b a-2.0-a", " "), actual)
 	}
-	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), "", "", map[string]interface{}{
+	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), nil, "", "", map[string]interface{}{
 		"prefix": "a",
 		"suffix": "a",
 	})
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("a-2.0-a", " "), actual)
 	}
-	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), "", "", map[string]interface{}{
+	actual, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), nil, "", "", map[string]interface{}{
 		"prefix": []interface{}{"a", ""},
 		"suffix": []interface{}{"b", ""},
 	})
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("a-2.0-b 2.0", " "), actual)
 	}
-	_, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), "", "", map[string]interface{}{
+	_, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), nil, "", "", map[string]interface{}{
 		"suffix": 23,
 	})
 	assert.EqualError(t, err, "filter must either be a string or a string list")
-	_, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), "", "", map[string]interface{}{
+	_, err = p4.FilterAndSort("1.0", strings.Split("2.0 a-2.0-a a-2.0-b b-2.0-a b-2.0-b", " "), nil, "", "", map[string]interface{}{
 		"suffix": []interface{}{23},
 	})
 	assert.EqualError(t, err, "filter must either be a string or a string list")
@@ -253,23 +254,34 @@ func TestPolicyFilterAndSort(t *testing.T) {
 		},
 	}
 
-	actual, err = p5.FilterAndSort("1.0.0", strings.Split("2.0.0+a 2.0.0+b 2.0.0+c", " "), "", "", map[string]interface{}{
+	actual, err = p5.FilterAndSort("1.0.0", strings.Split("2.0.0+a 2.0.0+b 2.0.0+c", " "), nil, "", "", map[string]interface{}{
 		"key.build": "b",
 	})
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.0.0+b", " "), actual)
 	}
-	actual, err = p5.FilterAndSort("1.0.0", strings.Split("2.0.0+a 2.0.0+b 2.0.0+c", " "), "", "", map[string]interface{}{
+	actual, err = p5.FilterAndSort("1.0.0", strings.Split("2.0.0+a 2.0.0+b 2.0.0+c", " "), nil, "", "", map[string]interface{}{
 		"key.build": []interface{}{"a", "c"},
 	})
 	if assert.NoError(t, err) {
 		assert.Equal(t, strings.Split("2.0.0+c 2.0.0+a", " "), actual)
 	}
+
+	metadata := map[string]VersionMetadata{
+		"2.0.0+a": {Prerelease: false},
+		"2.0.0+b": {Prerelease: true},
+	}
+	actual, err = p5.FilterAndSort("1.0.0", strings.Split("2.0.0+a 2.0.0+b 2.0.0+c", " "), metadata, "", "", map[string]interface{}{
+		"registry.prerelease": "false",
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, strings.Split("2.0.0+a", " "), actual)
+	}
 }
 
 func TestPolicyFindNext(t *testing.T) {
 	p1 := Policy{}
-	actual, err := p1.FindNext("1", []string{"1", "2", "3"}, "", "", nil)
+	actual, err := p1.FindNext("1", []string{"1", "2", "3"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "1", *actual)
 	}
@@ -287,19 +299,19 @@ func TestPolicyFindNext(t *testing.T) {
 			},
 		},
 	}
-	actual, err = p2.FindNext("1.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, "", "", nil)
+	actual, err = p2.FindNext("1.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0", *actual)
 	}
-	actual, err = p2.FindNext("1.1", []string{"1.0", "1.1", "2.0", "2.0-pre"}, "", "", nil)
+	actual, err = p2.FindNext("1.1", []string{"1.0", "1.1", "2.0", "2.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0", *actual)
 	}
-	actual, err = p2.FindNext("2.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, "", "", nil)
+	actual, err = p2.FindNext("2.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0", *actual)
 	}
-	actual, err = p2.FindNext("3.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, "", "", nil)
+	actual, err = p2.FindNext("3.0", []string{"1.0", "1.1", "2.0", "2.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "3.0", *actual)
 	}
@@ -313,19 +325,19 @@ func TestPolicyFindNext(t *testing.T) {
 			},
 		},
 	}
-	actual, err = p3.FindNext("1.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, "", "", nil)
+	actual, err = p3.FindNext("1.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0.0", *actual)
 	}
-	actual, err = p3.FindNext("1.1.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, "", "", nil)
+	actual, err = p3.FindNext("1.1.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0.0", *actual)
 	}
-	actual, err = p3.FindNext("2.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, "", "", nil)
+	actual, err = p3.FindNext("2.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "2.0.0", *actual)
 	}
-	actual, err = p3.FindNext("3.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, "", "", nil)
+	actual, err = p3.FindNext("3.0.0", []string{"1.0.0", "1.1.0", "2.0.0", "2.0.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "3.0.0", *actual)
 	}
@@ -340,10 +352,120 @@ func TestPolicyFindNext(t *testing.T) {
 		},
 	}
 
-	actual, err = p4.FindNext("0.10.4-pre", []string{"0.10.0", "0.10.1", "0.10.2", "0.10.3", "0.11.0-pre"}, "", "", nil)
+	actual, err = p4.FindNext("0.10.4-pre", []string{"0.10.0", "0.10.1", "0.10.2", "0.10.3", "0.11.0-pre"}, nil, "", "", nil)
 	if assert.NoError(t, err) {
 		assert.Equal(t, "0.10.4-pre", *actual)
 	}
+
+	p5 := Policy{
+		Pattern: regexp.MustCompile(`^(?P<all>.*)$`),
+		Extracts: []Extract{
+			{
+				Value:    "<all>",
+				Strategy: SemverExtractStrategy{},
+			},
+		},
+		MinAge: 72 * time.Hour,
+	}
+	now := time.Now()
+	metadata := map[string]VersionMetadata{
+		"1.0.0": {Published: now.Add(-1 * time.Hour)},
+		"1.1.0": {Published: now.Add(-100 * time.Hour)},
+		"1.2.0": {Published: now.Add(-80 * time.Hour)},
+		"1.3.0": {Published: now.Add(-2 * time.Hour)},
+	}
+	actual, err = p5.FindNext("1.0.0", []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"}, metadata, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.2.0", *actual)
+	}
+	actual, err = p5.FindNext("1.2.0", []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"}, metadata, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.2.0", *actual)
+	}
+	actual, err = p5.FindNext("1.3.0", []string{"1.0.0", "1.1.0", "1.2.0", "1.3.0", "1.4.0"}, metadata, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.3.0", *actual)
+	}
+
+	constraint, err := ParseSemverConstraint(">=1.24 <1.28")
+	assert.NoError(t, err)
+	p6 := Policy{
+		Pattern: regexp.MustCompile(`^v(?P<all>.*)$`),
+		Extracts: []Extract{
+			{
+				Value:    "<all>",
+				Strategy: SemverExtractStrategy{Relaxed: true, Constraint: constraint},
+			},
+		},
+	}
+	actual, err = p6.FindNext("v1.24", []string{"v1.24", "v1.25", "v1.27.3", "v1.28", "v1.29"}, nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "v1.27.3", *actual)
+	}
+	_, err = p6.FindNext("v1.29", []string{"v1.24", "v1.25", "v1.27.3", "v1.28", "v1.29"}, nil, "", "", nil)
+	assert.EqualError(t, err, "current version v1.29: 1.29.0 is outside of constraint >=1.24 <1.28")
+
+	constraint2, err := ParseSemverConstraint("~1.25")
+	assert.NoError(t, err)
+	p7, err := p6.WithSemverConstraint(constraint2)
+	if assert.NoError(t, err) {
+		actual, err = p7.FindNext("v1.25", []string{"v1.24", "v1.25", "v1.25.4", "v1.27.3"}, nil, "", "", nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "v1.25.4", *actual)
+		}
+	}
+	_, err = p2.WithSemverConstraint(constraint2)
+	assert.Error(t, err)
+
+	p8 := Policy{
+		Extracts: []Extract{
+			{
+				Strategy: SemverExtractStrategy{},
+			},
+		},
+		Step: PolicyStepMinor,
+	}
+	versions := []string{"1.24.0", "1.24.3", "1.25.0", "1.25.7", "1.26.1", "2.0.0", "2.1.0"}
+	actual, err = p8.FindNext("1.24.0", versions, nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.25.7", *actual)
+	}
+	actual, err = p8.FindNext("1.25.0", versions, nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1.26.1", *actual)
+	}
+	actual, err = p8.FindNext("1.26.0", versions, nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.0.0", *actual)
+	}
+	actual, err = p8.FindNext("2.1.0", append(versions, "2.1.4"), nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.1.4", *actual)
+	}
+
+	p9 := Policy{
+		Extracts: []Extract{
+			{
+				Strategy: SemverExtractStrategy{},
+			},
+		},
+		Step: PolicyStepMajor,
+	}
+	actual, err = p9.FindNext("1.24.0", append(versions, "3.0.0"), nil, "", "", nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "2.1.0", *actual)
+	}
+
+	p10 := Policy{
+		Extracts: []Extract{
+			{
+				Strategy: NumericExtractStrategy{},
+			},
+		},
+		Step: PolicyStepMinor,
+	}
+	_, err = p10.FindNext("1", []string{"1", "2"}, nil, "", "", nil)
+	assert.Error(t, err)
 }
 
 func TestLexicographicSortStrategyCompare(t *testing.T) {
@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"context"
+	"fmt"
 	"time"
 )
 
@@ -11,5 +13,107 @@ type HttpBasicCredentials struct {
 
 type Registry interface {
 	GetInterval() time.Duration
-	FetchVersions(resource string) ([]string, error)
+	FetchVersions(ctx context.Context, resource string) ([]string, error)
+}
+
+type VersionMetadata struct {
+	Published  time.Time `yaml:"published,omitempty"`
+	Prerelease bool      `yaml:"prerelease,omitempty"`
+	Tags       []string  `yaml:"tags,omitempty"`
+}
+
+func (m VersionMetadata) Segments() map[string]string {
+	result := map[string]string{
+		"prerelease": fmt.Sprintf("%t", m.Prerelease),
+	}
+	for _, t := range m.Tags {
+		result["tag."+t] = "true"
+	}
+	return result
+}
+
+type RegistryFetchResult struct {
+	Versions []string
+	Metadata map[string]VersionMetadata
+	// Endpoint is the url that actually served the versions, in case the
+	// registry can fall back to alternatives
+	Endpoint string
+	// LastUpdated is when the resource as a whole has last been changed, for
+	// registries that know it without knowing about single versions
+	LastUpdated time.Time
+	// Skipped are listed entries that turned out not to be versions, so that
+	// incremental registries need not look at them again
+	Skipped []string
+}
+
+// MetadataRegistry is implemented by registries that know more about their
+// versions than just the name, like when and whether they were released.
+type MetadataRegistry interface {
+	Registry
+	FetchVersionsWithMetadata(ctx context.Context, resource string) (*RegistryFetchResult, error)
+}
+
+// IncrementalRegistry is implemented by registries that need an expensive
+// lookup for every listed entry and can reuse the outcome of the previous
+// fetch for the entries they have already seen.
+type IncrementalRegistry interface {
+	Registry
+	FetchVersionsIncremental(ctx context.Context, resource string, previous *RegistryFetchResult) (*RegistryFetchResult, error)
+}
+
+// DigestRegistry is implemented by registries that can resolve the
+// immutable content digest a version currently points to.
+type DigestRegistry interface {
+	Registry
+	FetchDigest(ctx context.Context, resource string, version string) (string, error)
+}
+
+// PublishedRegistry is implemented by registries that cannot list publish
+// times cheaply, but can look them up for single versions on demand.
+type PublishedRegistry interface {
+	Registry
+	FetchPublished(ctx context.Context, resource string, version string) (time.Time, error)
+}
+
+// EndpointRegistry is implemented by registries that can fall back to
+// alternative endpoints, so that lookups following a fetch can start with
+// the endpoint that served the versions.
+type EndpointRegistry interface {
+	Registry
+	WithPreferredEndpoint(endpoint string) Registry
+}
+
+func registryWithPreferredEndpoint(registry Registry, endpoint string) Registry {
+	if endpointRegistry, ok := registry.(EndpointRegistry); ok && endpoint != "" {
+		return endpointRegistry.WithPreferredEndpoint(endpoint)
+	}
+	return registry
+}
+
+// PublishTimeRegistry is implemented by registries that can tell when
+// versions have been published, which policies with a minimum age depend on.
+// ReportsPublished reflects how the registry has been configured, as not
+// every mode of a registry knows about publish times.
+type PublishTimeRegistry interface {
+	Registry
+	ReportsPublished() bool
+}
+
+func registryReportsPublished(registry Registry) bool {
+	publishTimeRegistry, ok := registry.(PublishTimeRegistry)
+	return ok && publishTimeRegistry.ReportsPublished()
+}
+
+func fetchVersions(ctx context.Context, registry Registry, resource string, previous *RegistryFetchResult) (*RegistryFetchResult, error) {
+	if incrementalRegistry, ok := registry.(IncrementalRegistry); ok {
+		return incrementalRegistry.FetchVersionsIncremental(ctx, resource, previous)
+	}
+	if metadataRegistry, ok := registry.(MetadataRegistry); ok {
+		return metadataRegistry.FetchVersionsWithMetadata(ctx, resource)
+	}
+	versions, err := registry.FetchVersions(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryFetchResult{Versions: versions}, nil
 }
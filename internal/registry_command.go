@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var _ Registry = (*CommandRegistry)(nil)
+
+type CommandRegistry struct {
+	Interval time.Duration
+	Command  []string
+	Dir      string
+	Timeout  time.Duration
+}
+
+const commandRegistryDefaultTimeout = time.Minute
+
+func (r CommandRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r CommandRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	if len(r.Command) == 0 {
+		return nil, fmt.Errorf("command must not be empty")
+	}
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = commandRegistryDefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	name := r.Command[0]
+	args := SliceMap(r.Command[1:], func(arg string) string {
+		return strings.ReplaceAll(arg, "<resource>", resource)
+	})
+	LogDebug("Executing %s with args [%s]", name, strings.Join(args, ", "))
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = r.Dir
+	cmd.Env = append(os.Environ(), "GIT_OPS_UPDATE_RESOURCE="+resource)
+	stdout := bytes.Buffer{}
+	stderr := bytes.Buffer{}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("executing %s timed out after %v", name, timeout)
+	}
+	if err != nil {
+		str := strings.Trim(stdout.String()+"\n"+stderr.String(), " \n")
+		if str != "" {
+			str = "\n" + str
+		}
+		return nil, fmt.Errorf("executing %s failed: %w%s", name, err, str)
+	}
+
+	result := []string{}
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			result = append(result, line)
+		}
+	}
+	return result, nil
+}
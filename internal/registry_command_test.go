@@ -0,0 +1,40 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandFetchVersions(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	err = os.WriteFile(dir+"/versions", []byte("1.0.0\n\n 1.1.0 \n"), 0o664)
+	assert.NoError(t, err)
+
+	reg := CommandRegistry{
+		Command: []string{"sh", "-c", `test "$1" = "$GIT_OPS_UPDATE_RESOURCE" && cat versions`, "sh", "<resource>"},
+		Dir:     dir,
+	}
+	output, err := reg.FetchVersions(context.Background(), "tool")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, output)
+	}
+
+	reg2 := CommandRegistry{
+		Command: []string{"sh", "-c", "echo broken >&2; exit 1"},
+	}
+	_, err = reg2.FetchVersions(context.Background(), "tool")
+	assert.EqualError(t, err, "executing sh failed: exit status 1\nbroken")
+
+	reg3 := CommandRegistry{
+		Command: []string{"sleep", "1"},
+		Timeout: 10 * time.Millisecond,
+	}
+	_, err = reg3.FetchVersions(context.Background(), "tool")
+	assert.EqualError(t, err, "executing sleep timed out after 10ms")
+}
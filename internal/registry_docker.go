@@ -1,10 +1,15 @@
 package internal
 
 import (
+	"context"
+	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strings"
 	"time"
@@ -12,30 +17,152 @@ import (
 	"github.com/heroku/docker-registry-client/registry"
 )
 
-var _ Registry = (*DockerRegistry)(nil)
+var _ MetadataRegistry = (*DockerRegistry)(nil)
+var _ DigestRegistry = (*DockerRegistry)(nil)
+var _ PublishedRegistry = (*DockerRegistry)(nil)
+var _ PublishTimeRegistry = (*DockerRegistry)(nil)
+var _ EndpointRegistry = (*DockerRegistry)(nil)
+
+const dockerManifestAccept = "application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.oci.image.manifest.v1+json"
 
 type DockerRegistry struct {
 	Interval    time.Duration
 	Url         string
 	Credentials HttpBasicCredentials
+	// DockerConfig resolves credentials from the docker cli config if no
+	// credentials have been given explicitly
+	DockerConfig bool
+	// Mirrors are tried in order before Url itself
+	Mirrors []DockerRegistryMirror
+	Http    HttpClientConfig
+	// preferredEndpoint is tried first if it is one of the endpoints
+	preferredEndpoint string
+}
+
+type DockerRegistryMirror struct {
+	Url          string
+	Credentials  HttpBasicCredentials
+	DockerConfig bool
+	// RepositoryPrefix is prepended to the repository, as pull-through
+	// caches like harbor serve upstream repositories inside a project
+	RepositoryPrefix string
 }
 
 func (r DockerRegistry) GetInterval() time.Duration {
 	return r.Interval
 }
 
-func (r DockerRegistry) FetchVersions(repository string) ([]string, error) {
+// ReportsPublished is true as the created time of the image config is
+// looked up on demand.
+func (r DockerRegistry) ReportsPublished() bool {
+	return true
+}
+
+// WithPreferredEndpoint returns a copy of the registry that tries the given
+// endpoint before the other ones.
+func (r DockerRegistry) WithPreferredEndpoint(endpoint string) Registry {
+	r.preferredEndpoint = endpoint
+	return r
+}
+
+func (r DockerRegistry) FetchVersions(ctx context.Context, repository string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r DockerRegistry) FetchVersionsWithMetadata(ctx context.Context, repository string) (*RegistryFetchResult, error) {
+	var versions []string
+	endpoint, err := r.withEndpoints(ctx, repository, "Fetching versions", func(endpoint DockerRegistry, repository string) error {
+		var err error
+		versions, err = endpoint.fetchTags(ctx, repository)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RegistryFetchResult{
+		Versions: versions,
+		Endpoint: endpoint,
+	}, nil
+}
+
+// FetchDigest resolves the content digest of the manifest the given tag
+// points to. For multi-arch images this is the digest of the index and not
+// of any platform specific manifest.
+func (r DockerRegistry) FetchDigest(ctx context.Context, repository string, version string) (string, error) {
+	var digest string
+	_, err := r.withEndpoints(ctx, repository, "Fetching digest", func(endpoint DockerRegistry, repository string) error {
+		var err error
+		digest, err = endpoint.fetchManifestDigest(ctx, repository, version)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return digest, nil
+}
+
+// withEndpoints runs fn against the mirrors and finally the registry itself
+// until one of them succeeds and returns the url of the serving endpoint.
+// The preferred endpoint, if any, goes first.
+func (r DockerRegistry) withEndpoints(ctx context.Context, repository string, operation string, fn func(endpoint DockerRegistry, repository string) error) (string, error) {
+	mirrors := append(append([]DockerRegistryMirror{}, r.Mirrors...), DockerRegistryMirror{
+		Url:          r.Url,
+		Credentials:  r.Credentials,
+		DockerConfig: r.DockerConfig,
+	})
+	for i, mirror := range mirrors {
+		if i > 0 && r.preferredEndpoint != "" && strings.TrimSuffix(mirror.Url, "/") == strings.TrimSuffix(r.preferredEndpoint, "/") {
+			mirrors = append(append([]DockerRegistryMirror{mirror}, mirrors[:i]...), mirrors[i+1:]...)
+			break
+		}
+	}
+	for i, mirror := range mirrors {
+		endpoint := DockerRegistry{
+			Url:          mirror.Url,
+			Credentials:  mirror.Credentials,
+			DockerConfig: mirror.DockerConfig,
+			Http:         r.Http,
+		}
+		mirrorRepository := repository
+		if mirror.RepositoryPrefix != "" {
+			mirrorRepository = strings.TrimSuffix(mirror.RepositoryPrefix, "/") + "/" + repository
+		}
+		err := fn(endpoint, mirrorRepository)
+		if err != nil {
+			if i < len(mirrors)-1 && dockerIsFailoverError(ctx, err) {
+				LogWarning("%s of %s from %s failed, falling back to next endpoint: %v", operation, mirrorRepository, mirror.Url, err)
+				continue
+			}
+			return "", err
+		}
+		return strings.TrimSuffix(mirror.Url, "/"), nil
+	}
+	return "", fmt.Errorf("no endpoint for %s available", repository)
+}
+
+func (r DockerRegistry) fetchTags(ctx context.Context, repository string) ([]string, error) {
 	type tagsPageJson struct {
 		Name string   `json:"name"`
 		Tags []string `json:"tags"`
 	}
-	client, url := r.createClient()
+	client, url, err := r.createClient(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	result := []string{}
 	nextLink := url + "/v2/" + repository + "/tags/list"
 
 	for nextLink != "" {
-		resp, err := client.Get(nextLink)
+		req, err := http.NewRequestWithContext(ctx, "GET", nextLink, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
 		if err != nil {
 			return nil, err
 		}
@@ -62,7 +189,101 @@ func (r DockerRegistry) FetchVersions(repository string) ([]string, error) {
 	return result, nil
 }
 
-func (r DockerRegistry) RetrieveLabels(repository string, version string) (map[string]string, error) {
+func (r DockerRegistry) fetchManifestDigest(ctx context.Context, repository string, version string) (string, error) {
+	client, url, err := r.createClient(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url+"/v2/"+repository+"/manifests/"+version, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Accept", dockerManifestAccept)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// some registries omit the digest header, so hash the manifest ourselves
+	req2, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/manifests/"+version, nil)
+	if err != nil {
+		return "", err
+	}
+	req2.Header.Add("Accept", dockerManifestAccept)
+	resp2, err := client.Do(req2)
+	if err != nil {
+		return "", err
+	}
+	defer resp2.Body.Close()
+	if digest := resp2.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	resp2Body, err := io.ReadAll(resp2.Body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(resp2Body)), nil
+}
+
+func (r DockerRegistry) RetrieveLabels(ctx context.Context, repository string, version string) (map[string]string, error) {
+	var configs []dockerImageConfigJson
+	_, err := r.withEndpoints(ctx, repository, "Retrieving labels", func(endpoint DockerRegistry, repository string) error {
+		var err error
+		configs, err = endpoint.fetchImageConfigs(ctx, repository, version)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	for _, config := range configs {
+		for k, v := range config.Config.Labels {
+			result[k] = v
+		}
+	}
+
+	return result, nil
+}
+
+// FetchPublished returns when the image behind the given tag has been
+// created. For multi-arch images the most recently created platform wins.
+func (r DockerRegistry) FetchPublished(ctx context.Context, repository string, version string) (time.Time, error) {
+	var configs []dockerImageConfigJson
+	_, err := r.withEndpoints(ctx, repository, "Fetching publish time", func(endpoint DockerRegistry, repository string) error {
+		var err error
+		configs, err = endpoint.fetchImageConfigs(ctx, repository, version)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	result := time.Time{}
+	for _, config := range configs {
+		if config.Created.After(result) {
+			result = config.Created
+		}
+	}
+
+	return result, nil
+}
+
+type dockerImageConfigJson struct {
+	Created time.Time `json:"created"`
+	Config  struct {
+		Labels map[string]string `json:"Labels"`
+		// rest omitted
+	} `json:"config"`
+	// rest omitted
+}
+
+func (r DockerRegistry) fetchImageConfigs(ctx context.Context, repository string, version string) ([]dockerImageConfigJson, error) {
 	type manifestConfigJson struct {
 		MediaType string `json:"mediaType"`
 		Digest    string `json:"digest"`
@@ -90,21 +311,16 @@ func (r DockerRegistry) RetrieveLabels(repository string, version string) (map[s
 		SchemaVersion int                        `json:"schemaVersion"`
 		Manifests     []manifestListManifestJson `json:"manifests"`
 	}
-	type configConfigJson struct {
-		Labels map[string]string `json:"Labels"`
-		// rest omitted
-	}
-	type configJson struct {
-		Config configConfigJson `json:"config"`
-		// rest omitted
+	client, url, err := r.createClient(ctx)
+	if err != nil {
+		return nil, err
 	}
-	client, url := r.createClient()
 
-	req, err := http.NewRequest("GET", url+"/v2/"+repository+"/manifests/"+version, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/manifests/"+version, nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("Accept", "application/vnd.docker.distribution.manifest.list.v2+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.oci.image.index.v1+json, application/vnd.oci.image.manifest.v1+json")
+	req.Header.Add("Accept", dockerManifestAccept)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -131,7 +347,7 @@ func (r DockerRegistry) RetrieveLabels(repository string, version string) (map[s
 			return nil, err
 		}
 		for _, m := range manifestList.Manifests {
-			req2, err := http.NewRequest("GET", url+"/v2/"+repository+"/manifests/"+m.Digest, nil)
+			req2, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/manifests/"+m.Digest, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -157,10 +373,10 @@ func (r DockerRegistry) RetrieveLabels(repository string, version string) (map[s
 		return nil, fmt.Errorf("unexpected content type %s", req.Header.Get("content-type"))
 	}
 
-	result := map[string]string{}
+	result := []dockerImageConfigJson{}
 
 	for _, m := range manifests {
-		req3, err := http.NewRequest("GET", url+"/v2/"+repository+"/blobs/"+m.Config.Digest, nil)
+		req3, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/blobs/"+m.Config.Digest, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -173,27 +389,33 @@ func (r DockerRegistry) RetrieveLabels(repository string, version string) (map[s
 		if err != nil {
 			return nil, err
 		}
-		var config configJson
+		var config dockerImageConfigJson
 		err = json.Unmarshal(resp3Body, &config)
 		if err != nil {
 			return nil, err
 		}
-		for k, v := range config.Config.Labels {
-			result[k] = v
-		}
+		result = append(result, config)
 	}
 
 	return result, nil
 }
 
-func (r DockerRegistry) createClient() (http.Client, string) {
+func (r DockerRegistry) createClient(ctx context.Context) (http.Client, string, error) {
 	url := strings.TrimSuffix(r.Url, "/")
 	username := r.Credentials.Username
 	password := r.Credentials.Password
+	if username == "" && r.DockerConfig {
+		credentials, err := dockerConfigLoadCredentials(ctx, url)
+		if err != nil {
+			return http.Client{}, "", fmt.Errorf("unable to load credentials for %s from docker config: %w", url, err)
+		}
+		username = credentials.Username
+		password = credentials.Password
+	}
 	client := http.Client{
-		Transport: dockerWrapTransport(http.DefaultTransport, url, username, password),
+		Transport: dockerWrapTransport(r.Http.WrapTransport(http.DefaultTransport), url, username, password),
 	}
-	return client, url
+	return client, url, nil
 }
 
 func dockerWrapTransport(transport http.RoundTripper, url, username, password string) http.RoundTripper {
@@ -214,6 +436,30 @@ func dockerWrapTransport(transport http.RoundTripper, url, username, password st
 	return errorTransport
 }
 
+// dockerIsFailoverError tells whether another endpoint might be able to
+// serve a request that failed with the given error, which is the case for
+// network issues, rate limiting and server side errors. Client errors and
+// TLS errors are not retried, and neither is anything once the context has
+// been cancelled.
+func dockerIsFailoverError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var statusErr *registry.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		code := statusErr.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	// url.Error implements net.Error itself, so look at what it wraps to tell
+	// connection problems apart from e.g. certificate errors
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
 func dockerGetNextLink(resp *http.Response) string {
 	regex := regexp.MustCompile(`^ *<?([^;>]+)>? *(?:;[^;]*)*; *rel="?next"?(?:;.*)?`)
 	for _, link := range resp.Header[http.CanonicalHeaderKey("Link")] {
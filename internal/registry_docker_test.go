@@ -1,7 +1,20 @@
 package internal
 
 import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -10,7 +23,7 @@ func TestDockerFetchVersions(t *testing.T) {
 	reg := DockerRegistry{
 		Url: "https://ghcr.io",
 	}
-	output, err := reg.FetchVersions("airfocusio/git-ops-update-test")
+	output, err := reg.FetchVersions(context.Background(), "airfocusio/git-ops-update-test")
 	assert.NoError(t, err)
 	assert.Greater(t, len(output), 0)
 
@@ -30,7 +43,7 @@ func TestDockerRetrieveLabels(t *testing.T) {
 	}
 
 	t.Run("docker-v2-manifest", func(t *testing.T) {
-		output, err := reg.RetrieveLabels("airfocusio/git-ops-update-test", "docker-v2-manifest-v0.0.1")
+		output, err := reg.RetrieveLabels(context.Background(), "airfocusio/git-ops-update-test", "docker-v2-manifest-v0.0.1")
 		assert.NoError(t, err)
 		assert.Equal(t, "0.0.1", output["org.opencontainers.image.version"])
 		assert.Equal(t, "https://github.com/airfocusio/git-ops-update", output["org.opencontainers.image.source"])
@@ -38,7 +51,7 @@ func TestDockerRetrieveLabels(t *testing.T) {
 	})
 
 	t.Run("oci-v1-manifest", func(t *testing.T) {
-		output, err := reg.RetrieveLabels("airfocusio/git-ops-update-test", "oci-v1-manifest-v0.0.1")
+		output, err := reg.RetrieveLabels(context.Background(), "airfocusio/git-ops-update-test", "oci-v1-manifest-v0.0.1")
 		assert.NoError(t, err)
 		assert.Equal(t, "0.0.1", output["org.opencontainers.image.version"])
 		assert.Equal(t, "https://github.com/airfocusio/git-ops-update", output["org.opencontainers.image.source"])
@@ -46,7 +59,7 @@ func TestDockerRetrieveLabels(t *testing.T) {
 	})
 
 	t.Run("docker-v2-manifest-list", func(t *testing.T) {
-		output, err := reg.RetrieveLabels("airfocusio/git-ops-update-test", "docker-v2-manifest-list-v0.0.1")
+		output, err := reg.RetrieveLabels(context.Background(), "airfocusio/git-ops-update-test", "docker-v2-manifest-list-v0.0.1")
 		assert.NoError(t, err)
 		assert.Equal(t, "0.0.1", output["org.opencontainers.image.version"])
 		assert.Equal(t, "https://github.com/airfocusio/git-ops-update", output["org.opencontainers.image.source"])
@@ -54,10 +67,186 @@ func TestDockerRetrieveLabels(t *testing.T) {
 	})
 
 	t.Run("oci-v1-image-index", func(t *testing.T) {
-		output, err := reg.RetrieveLabels("airfocusio/git-ops-update-test", "oci-v1-image-index-v0.0.1")
+		output, err := reg.RetrieveLabels(context.Background(), "airfocusio/git-ops-update-test", "oci-v1-image-index-v0.0.1")
 		assert.NoError(t, err)
 		assert.Equal(t, "0.0.1", output["org.opencontainers.image.version"])
 		assert.Equal(t, "https://github.com/airfocusio/git-ops-update", output["org.opencontainers.image.source"])
 		assert.Equal(t, "99a7aaa2eff5aff7c77d9caf0901454fedf6bf00", output["org.opencontainers.image.revision"])
 	})
 }
+
+func TestDockerFetchVersionsMirrors(t *testing.T) {
+	rateLimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer rateLimited.Close()
+	notFound := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer notFound.Close()
+	offline := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	offline.Close()
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/proxy/library/nginx/tags/list" && r.URL.Path != "/v2/library/nginx/tags/list" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"name": "library/nginx", "tags": ["1.20", "1.21"]}`))
+	}))
+	defer upstream.Close()
+
+	reg := DockerRegistry{
+		Url: upstream.URL,
+		Mirrors: []DockerRegistryMirror{
+			{Url: offline.URL},
+			{Url: rateLimited.URL},
+		},
+	}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "library/nginx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.20", "1.21"}, output.Versions)
+		assert.Equal(t, upstream.URL, output.Endpoint)
+	}
+
+	reg2 := DockerRegistry{
+		Url: rateLimited.URL,
+		Mirrors: []DockerRegistryMirror{
+			{Url: upstream.URL, RepositoryPrefix: "proxy/"},
+		},
+	}
+	output, err = reg2.FetchVersionsWithMetadata(context.Background(), "library/nginx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.20", "1.21"}, output.Versions)
+		assert.Equal(t, upstream.URL, output.Endpoint)
+	}
+
+	reg3 := DockerRegistry{
+		Url: upstream.URL,
+		Mirrors: []DockerRegistryMirror{
+			{Url: notFound.URL},
+		},
+	}
+	_, err = reg3.FetchVersions(context.Background(), "library/nginx")
+	assert.Error(t, err)
+
+	reg4 := DockerRegistry{
+		Url: rateLimited.URL,
+		Mirrors: []DockerRegistryMirror{
+			{Url: offline.URL},
+		},
+	}
+	_, err = reg4.FetchVersions(context.Background(), "library/nginx")
+	assert.Error(t, err)
+
+	untrusted := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer untrusted.Close()
+	reg5 := DockerRegistry{
+		Url: upstream.URL,
+		Mirrors: []DockerRegistryMirror{
+			{Url: untrusted.URL},
+		},
+	}
+	_, err = reg5.FetchVersions(context.Background(), "library/nginx")
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = reg.FetchVersions(ctx, "library/nginx")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.True(t, dockerIsFailoverError(context.Background(), &url.Error{Op: "Get", URL: offline.URL, Err: &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}}))
+	assert.False(t, dockerIsFailoverError(context.Background(), &url.Error{Op: "Get", URL: untrusted.URL, Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}))
+	assert.False(t, dockerIsFailoverError(context.Background(), &url.Error{Op: "Get", URL: offline.URL, Err: fmt.Errorf("unexpected response")}))
+	assert.False(t, dockerIsFailoverError(ctx, &url.Error{Op: "Get", URL: offline.URL, Err: context.Canceled}))
+}
+
+func TestDockerFetchDigest(t *testing.T) {
+	manifest := []byte(`{"schemaVersion": 2, "mediaType": "application/vnd.oci.image.index.v1+json", "manifests": []}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json") {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/1.21":
+			w.Header().Set("Docker-Content-Digest", "sha256:0123")
+			if r.Method == "GET" {
+				_, _ = w.Write(manifest)
+			}
+		case "/v2/library/redis/manifests/6.2":
+			if r.Method == "GET" {
+				_, _ = w.Write(manifest)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := DockerRegistry{Url: server.URL}
+	digest, err := reg.FetchDigest(context.Background(), "library/nginx", "1.21")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "sha256:0123", digest)
+	}
+
+	digest, err = reg.FetchDigest(context.Background(), "library/redis", "6.2")
+	if assert.NoError(t, err) {
+		assert.Equal(t, fmt.Sprintf("sha256:%x", sha256.Sum256(manifest)), digest)
+	}
+
+	_, err = reg.FetchDigest(context.Background(), "library/nginx", "1.22")
+	assert.Error(t, err)
+}
+
+func TestDockerFetchPublished(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/library/nginx/manifests/1.21":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.index.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion": 2, "manifests": [{"digest": "sha256:amd64"}, {"digest": "sha256:arm64"}]}`))
+		case "/v2/library/nginx/manifests/sha256:amd64", "/v2/library/nginx/manifests/sha256:arm64":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion": 2, "config": {"digest": "` + strings.TrimPrefix(r.URL.Path, "/v2/library/nginx/manifests/") + `-config"}}`))
+		case "/v2/library/nginx/blobs/sha256:amd64-config":
+			_, _ = w.Write([]byte(`{"created": "2023-01-01T10:00:00Z", "config": {"Labels": {"arch": "amd64"}}}`))
+		case "/v2/library/nginx/blobs/sha256:arm64-config":
+			_, _ = w.Write([]byte(`{"created": "2023-01-01T10:05:00Z", "config": {"Labels": {"arch": "arm64"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := DockerRegistry{Url: server.URL}
+	published, err := reg.FetchPublished(context.Background(), "library/nginx", "1.21")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2023, 1, 1, 10, 5, 0, 0, time.UTC), published)
+	}
+
+	_, err = reg.FetchPublished(context.Background(), "library/nginx", "1.22")
+	assert.Error(t, err)
+
+	mirrorRequests := atomic.Int32{}
+	rateLimited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrorRequests.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer rateLimited.Close()
+	reg2 := DockerRegistry{Url: server.URL, Mirrors: []DockerRegistryMirror{{Url: rateLimited.URL}}}
+	published, err = reg2.FetchPublished(context.Background(), "library/nginx", "1.21")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2023, 1, 1, 10, 5, 0, 0, time.UTC), published)
+	}
+	labels, err := reg2.RetrieveLabels(context.Background(), "library/nginx", "1.21")
+	if assert.NoError(t, err) {
+		assert.Contains(t, []string{"amd64", "arm64"}, labels["arch"])
+	}
+	assert.Equal(t, int32(2), mirrorRequests.Load())
+
+	mirrorRequests.Store(0)
+	published, err = reg2.WithPreferredEndpoint(server.URL+"/").(DockerRegistry).FetchPublished(context.Background(), "library/nginx", "1.21")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2023, 1, 1, 10, 5, 0, 0, time.UTC), published)
+	}
+	assert.Equal(t, int32(0), mirrorRequests.Load())
+}
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+var _ Registry = (*GitRegistry)(nil)
+
+type GitRegistry struct {
+	Interval    time.Duration
+	Url         string
+	Credentials HttpBasicCredentials
+}
+
+func (r GitRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r GitRegistry) FetchVersions(ctx context.Context, repository string) ([]string, error) {
+	url := repository
+	if r.Url != "" {
+		url = strings.TrimSuffix(r.Url, "/") + "/" + strings.TrimPrefix(repository, "/")
+	}
+	LogDebug("Fetching versions from git registry %s", url)
+
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+	})
+	listOptions := &git.ListOptions{
+		PeelingOption: git.AppendPeeled,
+	}
+	if r.Credentials.Username != "" {
+		listOptions.Auth = &http.BasicAuth{
+			Username: r.Credentials.Username,
+			Password: r.Credentials.Password,
+		}
+	}
+	refs, err := remote.ListContext(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list git tags of %s: %w", url, err)
+	}
+
+	result := []string{}
+	for _, ref := range refs {
+		refName := ref.Name().String()
+		if !strings.HasPrefix(refName, "refs/tags/") {
+			continue
+		}
+		// annotated tags are advertised a second time as "<tag>^{}" pointing to the tagged commit
+		result = append(result, strings.TrimSuffix(strings.TrimPrefix(refName, "refs/tags/"), "^{}"))
+	}
+
+	return SliceUnique(result), nil
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitFetchVersions(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	repo, err := git.PlainInit(dir, false)
+	assert.NoError(t, err)
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	err = os.WriteFile(path.Join(dir, "file"), []byte("1"), 0o664)
+	assert.NoError(t, err)
+	_, err = worktree.Add("file")
+	assert.NoError(t, err)
+	signature := &object.Signature{Name: "test", Email: "test", When: time.Now()}
+	commit, err := worktree.Commit("commit", &git.CommitOptions{Author: signature})
+	assert.NoError(t, err)
+	_, err = repo.CreateTag("v1.0.0", commit, nil)
+	assert.NoError(t, err)
+	_, err = repo.CreateTag("v1.1.0", commit, &git.CreateTagOptions{Tagger: signature, Message: "v1.1.0"})
+	assert.NoError(t, err)
+
+	reg := GitRegistry{}
+	output, err := reg.FetchVersions(context.Background(), dir)
+	if assert.NoError(t, err) {
+		sort.Strings(output)
+		assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, output)
+	}
+
+	reg2 := GitRegistry{Url: path.Dir(dir)}
+	output, err = reg2.FetchVersions(context.Background(), path.Base(dir))
+	if assert.NoError(t, err) {
+		assert.Len(t, output, 2)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), path.Join(dir, "unknown"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+var _ MetadataRegistry = (*GitHubReleaseRegistry)(nil)
+var _ PublishTimeRegistry = (*GitHubReleaseRegistry)(nil)
+
+type GitHubReleaseRegistry struct {
+	Interval    time.Duration
+	Url         string
+	Credentials HttpBasicCredentials
+	AccessToken string
+	Http        HttpClientConfig
+}
+
+type gitHubReleaseRegistryRelease struct {
+	TagName     string     `json:"tag_name"`
+	Name        string     `json:"name"`
+	Draft       bool       `json:"draft"`
+	Prerelease  bool       `json:"prerelease"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+func (r GitHubReleaseRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r GitHubReleaseRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r GitHubReleaseRegistry) FetchVersions(ctx context.Context, repository string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r GitHubReleaseRegistry) FetchVersionsWithMetadata(ctx context.Context, repository string) (*RegistryFetchResult, error) {
+	LogDebug("Fetching versions from github-release registry %s", repository)
+	baseUrl := gitHubApiBaseUrl(r.Url)
+	url := fmt.Sprintf("%s/repos/%s/releases?per_page=100", baseUrl, repository)
+
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
+	err := gitHubApiGetPages(ctx, url, r.Credentials, r.AccessToken, r.Http, func(body []byte) error {
+		releases := []gitHubReleaseRegistryRelease{}
+		err := json.Unmarshal(body, &releases)
+		if err != nil {
+			return err
+		}
+		for _, release := range releases {
+			if release.Draft || release.TagName == "" {
+				continue
+			}
+			metadata := VersionMetadata{Prerelease: release.Prerelease}
+			if release.PublishedAt != nil {
+				metadata.Published = *release.PublishedAt
+			}
+			result.Versions = append(result.Versions, release.TagName)
+			result.Metadata[release.TagName] = metadata
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
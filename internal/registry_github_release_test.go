@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubReleaseFetchVersions(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/repo/releases" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"tag_name":"v1.0.0","draft":false,"prerelease":false,"published_at":"2021-01-01T00:00:00Z"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/releases?per_page=100&page=2>; rel="next"`, server.URL))
+		_, _ = w.Write([]byte(`[
+			{"tag_name":"v1.2.0","draft":true,"prerelease":false,"published_at":null},
+			{"tag_name":"v1.1.0-rc.1","draft":false,"prerelease":true,"published_at":"2021-02-01T00:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	reg := GitHubReleaseRegistry{Url: server.URL}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "owner/repo")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0-rc.1", "v1.0.0"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{
+			"v1.1.0-rc.1": {Prerelease: true, Published: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)},
+			"v1.0.0":      {Prerelease: false, Published: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}, output.Metadata)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "owner/unknown")
+	assert.Error(t, err)
+}
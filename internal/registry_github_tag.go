@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -15,6 +17,8 @@ type GitHubTagRegistry struct {
 	Interval    time.Duration
 	Url         string
 	Credentials HttpBasicCredentials
+	AccessToken string
+	Http        HttpClientConfig
 }
 
 type gitHubTagRegistryRef struct {
@@ -23,54 +27,127 @@ type gitHubTagRegistryRef struct {
 	Url    string `json:"url"`
 }
 
+const gitHubRateLimitMaxWait = 5 * time.Minute
+const gitHubRateLimitMaxRetries = 3
+
+var gitHubRateLimitSleep = sleepContext
+
 func (r GitHubTagRegistry) GetInterval() time.Duration {
 	return r.Interval
 }
 
-func (r GitHubTagRegistry) FetchVersions(repository string) ([]string, error) {
+func (r GitHubTagRegistry) FetchVersions(ctx context.Context, repository string) ([]string, error) {
 	LogDebug("Fetching versions from github-tag registry %s", repository)
-	baseUrl := "https://api.github.com"
-	if r.Url != "" {
-		baseUrl = strings.TrimSuffix(r.Url, "/")
-	}
-	url := fmt.Sprintf("%s/repos/%s/git/matching-refs/tags", baseUrl, repository)
+	baseUrl := gitHubApiBaseUrl(r.Url)
+	url := fmt.Sprintf("%s/repos/%s/git/matching-refs/tags?per_page=100", baseUrl, repository)
 
-	username := r.Credentials.Username
-	password := r.Credentials.Password
-	req, err := http.NewRequest("GET", url, nil)
-	client := &http.Client{}
+	result := []string{}
+	err := gitHubApiGetPages(ctx, url, r.Credentials, r.AccessToken, r.Http, func(body []byte) error {
+		refs := []gitHubTagRegistryRef{}
+		err := json.Unmarshal(body, &refs)
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			if strings.HasPrefix(ref.Ref, "refs/tags/") {
+				result = append(result, strings.TrimPrefix(ref.Ref, "refs/tags/"))
+			}
+		}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	if username != "" {
-		req.SetBasicAuth(username, password)
-	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
-		return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
-	}
+	return result, nil
+}
 
-	refs := []gitHubTagRegistryRef{}
-	err = json.Unmarshal(body, &refs)
-	if err != nil {
-		return nil, err
+func gitHubApiBaseUrl(url string) string {
+	if url != "" {
+		return strings.TrimSuffix(url, "/")
 	}
+	return "https://api.github.com"
+}
 
-	result := []string{}
-	for _, ref := range refs {
-		if strings.HasPrefix(ref.Ref, "refs/tags/") {
-			result = append(result, strings.TrimPrefix(ref.Ref, "refs/tags/"))
+// gitHubApiGetPages requests the given url and follows the Link header to all
+// subsequent pages. Responses that indicate an exceeded rate limit are retried
+// after the announced reset, as long as this does not exceed gitHubRateLimitMaxWait.
+func gitHubApiGetPages(ctx context.Context, url string, credentials HttpBasicCredentials, accessToken string, httpConfig HttpClientConfig, pageFn func(body []byte) error) error {
+	// rate limits are only retried here, so that the http client does not
+	// multiply the attempts
+	httpConfig.handlesRateLimits = true
+	client := httpConfig.NewClient()
+	nextLink := url
+	retries := 0
+
+	for nextLink != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", nextLink, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		if accessToken != "" {
+			req.Header.Set("Authorization", "Bearer "+accessToken)
+		} else if credentials.Username != "" {
+			req.SetBasicAuth(credentials.Username, credentials.Password)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		// close every page right away instead of keeping all of them open
+		// until the last page has been read
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+		if wait, limited := gitHubRateLimitWait(resp, time.Now()); limited {
+			if retries >= gitHubRateLimitMaxRetries || wait > gitHubRateLimitMaxWait {
+				return fmt.Errorf("request GET %s failed because the github rate limit has been exceeded (resets in %v)", nextLink, wait.Round(time.Second))
+			}
+			LogWarning("Github rate limit has been exceeded, waiting %v", wait.Round(time.Second))
+			err = gitHubRateLimitSleep(ctx, wait)
+			if err != nil {
+				return err
+			}
+			retries = retries + 1
+			continue
+		}
+		if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+			return fmt.Errorf("request GET %s failed with status code %d", nextLink, resp.StatusCode)
+		}
+		retries = 0
+
+		err = pageFn(body)
+		if err != nil {
+			return err
 		}
+
+		nextLink = dockerGetNextLink(resp)
 	}
 
-	return result, nil
+	return nil
+}
+
+func gitHubRateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			wait := time.Unix(reset, 0).Sub(now)
+			if wait < 0 {
+				wait = 0
+			}
+			return wait, true
+		}
+	}
+	return 0, false
 }
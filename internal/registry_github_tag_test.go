@@ -0,0 +1,81 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitHubTagFetchVersions(t *testing.T) {
+	sleeps := []time.Duration{}
+	gitHubRateLimitSleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	defer func() { gitHubRateLimitSleep = sleepContext }()
+
+	requests := 0
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = requests + 1
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if requests == 2 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"ref":"refs/tags/v1.1.0"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s/repos/owner/repo/git/matching-refs/tags?page=2>; rel="next"`, server.URL))
+		_, _ = w.Write([]byte(`[{"ref":"refs/tags/v1.0.0"},{"ref":"refs/heads/main"}]`))
+	}))
+	defer server.Close()
+
+	reg := GitHubTagRegistry{Url: server.URL, AccessToken: "token"}
+	output, err := reg.FetchVersions(context.Background(), "owner/repo")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.0.0", "v1.1.0"}, output)
+		assert.Equal(t, []time.Duration{3 * time.Second}, sleeps)
+	}
+
+	reg2 := GitHubTagRegistry{Url: server.URL}
+	_, err = reg2.FetchVersions(context.Background(), "owner/repo")
+	assert.Error(t, err)
+}
+
+func TestGitHubRateLimitWait(t *testing.T) {
+	now := time.Unix(1000, 0)
+	header := func(kv ...string) http.Header {
+		h := http.Header{}
+		for i := 0; i < len(kv); i += 2 {
+			h.Set(kv[i], kv[i+1])
+		}
+		return h
+	}
+
+	_, limited := gitHubRateLimitWait(&http.Response{StatusCode: 200, Header: header("Retry-After", "1")}, now)
+	assert.False(t, limited)
+
+	_, limited = gitHubRateLimitWait(&http.Response{StatusCode: 403, Header: header()}, now)
+	assert.False(t, limited)
+
+	wait, limited := gitHubRateLimitWait(&http.Response{StatusCode: 429, Header: header("Retry-After", "10")}, now)
+	assert.True(t, limited)
+	assert.Equal(t, 10*time.Second, wait)
+
+	wait, limited = gitHubRateLimitWait(&http.Response{StatusCode: 403, Header: header("X-RateLimit-Remaining", "0", "X-RateLimit-Reset", strconv.Itoa(1060))}, now)
+	assert.True(t, limited)
+	assert.Equal(t, time.Minute, wait)
+}
@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+)
+
+var _ MetadataRegistry = (*GitLabTagRegistry)(nil)
+var _ PublishTimeRegistry = (*GitLabTagRegistry)(nil)
+
+type GitLabTagRegistry struct {
+	Interval    time.Duration
+	Url         string
+	AccessToken string
+	Releases    bool
+}
+
+func (r GitLabTagRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r GitLabTagRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r GitLabTagRegistry) FetchVersions(ctx context.Context, project string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r GitLabTagRegistry) FetchVersionsWithMetadata(ctx context.Context, project string) (*RegistryFetchResult, error) {
+	LogDebug("Fetching versions from gitlab-tag registry %s", project)
+	baseUrl := "https://gitlab.com"
+	if r.Url != "" {
+		baseUrl = r.Url
+	}
+	client, err := gitlab.NewClient(r.AccessToken, gitlab.WithBaseURL(baseUrl))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to gitlab: %w", err)
+	}
+
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
+	if r.Releases {
+		opts := &gitlab.ListReleasesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+		for {
+			releases, res, err := client.Releases.ListReleases(project, opts, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("unable to list releases of gitlab project %s: %w", project, err)
+			}
+			for _, release := range releases {
+				if release.UpcomingRelease {
+					continue
+				}
+				metadata := VersionMetadata{}
+				if release.ReleasedAt != nil {
+					metadata.Published = *release.ReleasedAt
+				}
+				result.Versions = append(result.Versions, release.TagName)
+				result.Metadata[release.TagName] = metadata
+			}
+			if res.NextPage == 0 {
+				break
+			}
+			opts.Page = res.NextPage
+		}
+	} else {
+		opts := &gitlab.ListTagsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+		for {
+			tags, res, err := client.Tags.ListTags(project, opts, gitlab.WithContext(ctx))
+			if err != nil {
+				return nil, fmt.Errorf("unable to list tags of gitlab project %s: %w", project, err)
+			}
+			for _, tag := range tags {
+				metadata := VersionMetadata{}
+				if tag.CreatedAt != nil {
+					metadata.Published = *tag.CreatedAt
+				} else if tag.Commit != nil && tag.Commit.CommittedDate != nil {
+					metadata.Published = *tag.Commit.CommittedDate
+				}
+				result.Versions = append(result.Versions, tag.Name)
+				result.Metadata[tag.Name] = metadata
+			}
+			if res.NextPage == 0 {
+				break
+			}
+			opts.Page = res.NextPage
+		}
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,53 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLabTagFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/api/v4/projects/group%2Fproject/repository/tags":
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`[{"name":"v1.0.0","commit":{"committed_date":"2021-01-01T00:00:00Z"}}]`))
+				return
+			}
+			w.Header().Set("X-Next-Page", "2")
+			_, _ = w.Write([]byte(`[{"name":"v1.1.0","created_at":"2021-02-01T00:00:00Z"}]`))
+		case "/api/v4/projects/group%2Fproject/releases":
+			_, _ = w.Write([]byte(`[{"tag_name":"v1.2.0","upcoming_release":true},{"tag_name":"v1.1.0","released_at":"2021-02-02T00:00:00Z"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := GitLabTagRegistry{Url: server.URL, AccessToken: "token"}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "group/project")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0", "v1.0.0"}, output.Versions)
+		assert.Equal(t, time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), output.Metadata["v1.1.0"].Published)
+		assert.Equal(t, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), output.Metadata["v1.0.0"].Published)
+	}
+
+	reg2 := GitLabTagRegistry{Url: server.URL, AccessToken: "token", Releases: true}
+	output, err = reg2.FetchVersionsWithMetadata(context.Background(), "group/project")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v1.1.0"}, output.Versions)
+		assert.Equal(t, time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC), output.Metadata["v1.1.0"].Published)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "group/unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var _ Registry = (*GoProxyRegistry)(nil)
+
+type GoProxyRegistry struct {
+	Interval          time.Duration
+	Url               string
+	Credentials       HttpBasicCredentials
+	AllowIncompatible bool
+	Http              HttpClientConfig
+}
+
+// same as golang.org/x/mod/module.IsPseudoVersion
+var goProxyPseudoVersionRegex = regexp.MustCompile(`^v[0-9]+\.(0\.0-|\d+\.\d+-([^+]*\.)?0\.)\d{14}-[A-Za-z0-9]+(\+[0-9A-Za-z-]+(\.[0-9A-Za-z-]+)*)?$`)
+
+const goProxyFilePrefix = "file://"
+
+func (r GoProxyRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r GoProxyRegistry) FetchVersions(ctx context.Context, module string) ([]string, error) {
+	LogDebug("Fetching versions from go-proxy registry %s", module)
+	baseUrl := "https://proxy.golang.org"
+	if r.Url != "" {
+		baseUrl = strings.TrimSuffix(r.Url, "/")
+	}
+	escapedModule, err := goProxyEscapePath(module)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if strings.HasPrefix(baseUrl, goProxyFilePrefix) {
+		file := filepath.Join(strings.TrimPrefix(baseUrl, goProxyFilePrefix), filepath.FromSlash(escapedModule), "@v", "list")
+		body, err = os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		url := baseUrl + "/" + escapedModule + "/@v/list"
+		username := r.Credentials.Username
+		password := r.Credentials.Password
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		client := r.Http.NewClient()
+		if err != nil {
+			return nil, err
+		}
+		if username != "" {
+			req.SetBasicAuth(username, password)
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		body, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+			return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
+		}
+	}
+
+	result := []string{}
+	for _, line := range strings.Split(string(body), "\n") {
+		version := strings.TrimSpace(line)
+		if version == "" {
+			continue
+		}
+		if goProxyPseudoVersionRegex.MatchString(version) {
+			continue
+		}
+		if !r.AllowIncompatible && strings.HasSuffix(version, "+incompatible") {
+			continue
+		}
+		result = append(result, version)
+	}
+	return SliceUnique(result), nil
+}
+
+// goProxyEscapePath replaces every upper case letter with an exclamation mark
+// followed by the lower case letter, as case-insensitive file systems would
+// otherwise mix up modules like github.com/Azure and github.com/azure.
+func goProxyEscapePath(path string) (string, error) {
+	result := strings.Builder{}
+	for _, r := range path {
+		if r == '!' || r >= unicode.MaxASCII {
+			return "", fmt.Errorf("module path %s contains invalid character %q", path, r)
+		}
+		if unicode.IsUpper(r) {
+			result.WriteRune('!')
+			result.WriteRune(unicode.ToLower(r))
+		} else {
+			result.WriteRune(r)
+		}
+	}
+	return result.String(), nil
+}
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoProxyFetchVersions(t *testing.T) {
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	listDir := filepath.Join(dir, "github.com", "!burnt!sushi", "toml", "@v")
+	assert.NoError(t, os.MkdirAll(listDir, 0o755))
+	list := "v0.1.0\nv0.2.0\nv0.0.0-20190101000000-abcdefabcdef\nv0.3.1-0.20200101000000-abcdefabcdef\nv2.0.0+incompatible\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(listDir, "list"), []byte(list), 0o644))
+
+	reg := GoProxyRegistry{Url: "file://" + dir}
+	output, err := reg.FetchVersions(context.Background(), "github.com/BurntSushi/toml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v0.1.0", "v0.2.0"}, output)
+	}
+
+	reg2 := GoProxyRegistry{Url: "file://" + dir, AllowIncompatible: true}
+	output, err = reg2.FetchVersions(context.Background(), "github.com/BurntSushi/toml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"v0.1.0", "v0.2.0", "v2.0.0+incompatible"}, output)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "github.com/unknown/module")
+	assert.Error(t, err)
+}
+
+func TestGoProxyEscapePath(t *testing.T) {
+	actual, err := goProxyEscapePath("github.com/BurntSushi/toml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "github.com/!burnt!sushi/toml", actual)
+	}
+	actual, err = goProxyEscapePath("golang.org/x/tools")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "golang.org/x/tools", actual)
+	}
+	_, err = goProxyEscapePath("github.com/foo!bar")
+	assert.Error(t, err)
+}
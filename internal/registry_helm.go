@@ -1,41 +1,76 @@
 package internal
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
-var _ Registry = (*HelmRegistry)(nil)
+var _ MetadataRegistry = (*HelmRegistry)(nil)
+var _ PublishedRegistry = (*HelmRegistry)(nil)
+var _ IncrementalRegistry = (*HelmRegistry)(nil)
+var _ PublishTimeRegistry = (*HelmRegistry)(nil)
 
 type HelmRegistry struct {
 	Interval    time.Duration
 	Url         string
 	Credentials HttpBasicCredentials
+	PlainHttp   bool
+	Http        HttpClientConfig
 }
 
 type helmRegistryIndex struct {
 	ApiVersion string `yaml:"apiVersion"`
 	Entries    map[string][]struct {
-		ApiVersion string `yaml:"apiVersion"`
-		AppVersion string `yaml:"appVersion"`
-		Name       string `yaml:"name"`
-		Version    string `yaml:"version"`
+		ApiVersion string    `yaml:"apiVersion"`
+		AppVersion string    `yaml:"appVersion"`
+		Name       string    `yaml:"name"`
+		Version    string    `yaml:"version"`
+		Created    time.Time `yaml:"created"`
 	} `yaml:"entries"`
 }
 
+const helmOciPrefix = "oci://"
+const helmOciChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+const helmOciCreatedAnnotation = "org.opencontainers.image.created"
+
+// helmOciManifestConcurrency limits the manifest lookups that run at once
+// against a single OCI repository
+const helmOciManifestConcurrency = 8
+
 func (r HelmRegistry) GetInterval() time.Duration {
 	return r.Interval
 }
 
-func (r HelmRegistry) FetchVersions(chart string) ([]string, error) {
+// ReportsPublished is true as chart indexes list created times and those of
+// OCI charts are looked up on demand.
+func (r HelmRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r HelmRegistry) FetchVersions(ctx context.Context, chart string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, chart)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r HelmRegistry) FetchVersionsWithMetadata(ctx context.Context, chart string) (*RegistryFetchResult, error) {
+	if strings.HasPrefix(r.Url, helmOciPrefix) {
+		return r.fetchOciVersions(ctx, chart, nil)
+	}
+
 	url := strings.TrimSuffix(r.Url, "/") + "/index.yaml"
 	username := r.Credentials.Username
 	password := r.Credentials.Password
-	req, err := http.NewRequest("GET", url, nil)
-	client := &http.Client{}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
 	if err != nil {
 		return nil, err
 	}
@@ -62,10 +97,215 @@ func (r HelmRegistry) FetchVersions(chart string) ([]string, error) {
 	if !ok {
 		return nil, fmt.Errorf("chart %s could not be found", chart)
 	}
-	result := []string{}
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
 	for _, version := range versions {
-		result = append(result, version.Version)
+		result.Versions = append(result.Versions, version.Version)
+		result.Metadata[version.Version] = VersionMetadata{
+			Published: version.Created,
+		}
+	}
+
+	return &result, nil
+}
+
+// FetchVersionsIncremental only looks at the manifests of OCI tags that
+// have not been seen in the previous fetch, as tags are immutable in
+// practice. Index based repositories are always read completely.
+func (r HelmRegistry) FetchVersionsIncremental(ctx context.Context, chart string, previous *RegistryFetchResult) (*RegistryFetchResult, error) {
+	if !strings.HasPrefix(r.Url, helmOciPrefix) {
+		return r.FetchVersionsWithMetadata(ctx, chart)
+	}
+	return r.fetchOciVersions(ctx, chart, previous)
+}
+
+func (r HelmRegistry) fetchOciVersions(ctx context.Context, chart string, previous *RegistryFetchResult) (*RegistryFetchResult, error) {
+	url, repository := helmOciSplitUrl(r.Url, chart, r.PlainHttp)
+	LogDebug("Fetching versions from helm oci registry %s/%s", url, repository)
+	dockerRegistry := DockerRegistry{
+		Url:         url,
+		Credentials: r.Credentials,
+		Http:        r.Http,
+	}
+	tags, err := dockerRegistry.FetchVersions(ctx, repository)
+	if err != nil {
+		return nil, err
+	}
+	client, url, err := dockerRegistry.createClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tags of a repository might also be container images or other
+	// artifacts, so the manifest of every tag not seen before is looked at
+	isChart := map[string]bool{}
+	if previous != nil {
+		for _, version := range previous.Versions {
+			isChart[helmOciTag(version)] = true
+		}
+		for _, tag := range previous.Skipped {
+			isChart[tag] = false
+		}
+	}
+	unknownTags := []string{}
+	for _, tag := range tags {
+		if _, ok := isChart[tag]; !ok {
+			unknownTags = append(unknownTags, tag)
+		}
+	}
+	manifests := make([]*helmOciManifest, len(unknownTags))
+	errs := make([]error, len(unknownTags))
+	semaphore := make(chan struct{}, helmOciManifestConcurrency)
+	wg := sync.WaitGroup{}
+	for i, tag := range unknownTags {
+		wg.Add(1)
+		go func(i int, tag string) {
+			defer wg.Done()
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-semaphore }()
+			manifests[i], errs[i] = helmOciFetchManifest(ctx, client, url, repository, tag)
+		}(i, tag)
+	}
+	wg.Wait()
+	for i, tag := range unknownTags {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		manifest := manifests[i]
+		isChart[tag] = manifest.Config.MediaType == helmOciChartConfigMediaType || manifest.ArtifactType == helmOciChartConfigMediaType
 	}
 
-	return result, nil
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+		Skipped:  []string{},
+	}
+	for _, tag := range tags {
+		if !isChart[tag] {
+			LogDebug("Skipping tag %s of %s as it is not a helm chart", tag, repository)
+			result.Skipped = append(result.Skipped, tag)
+			continue
+		}
+		// OCI tags do not allow "+", so helm replaces it with "_" when pushing
+		version := strings.ReplaceAll(tag, "_", "+")
+		result.Versions = append(result.Versions, version)
+		// publish times are looked up on demand, but the ones already known
+		// are kept
+		if previous != nil {
+			if metadata, ok := previous.Metadata[version]; ok {
+				result.Metadata[version] = metadata
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+type helmOciManifest struct {
+	MediaType    string `json:"mediaType"`
+	ArtifactType string `json:"artifactType"`
+	Config       struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"config"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+func helmOciFetchManifest(ctx context.Context, client http.Client, url string, repository string, tag string) (*helmOciManifest, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/manifests/"+tag, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/vnd.oci.image.manifest.v1+json, application/vnd.oci.image.index.v1+json, application/vnd.docker.distribution.manifest.v2+json, application/vnd.docker.distribution.manifest.list.v2+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := helmOciManifest{}
+	err = json.Unmarshal(respBody, &manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &manifest, nil
+}
+
+// FetchPublished looks up when an OCI chart has been created, either from
+// the annotation on its manifest or from its config. For index based
+// repositories all known publish times are already part of the index.
+func (r HelmRegistry) FetchPublished(ctx context.Context, chart string, version string) (time.Time, error) {
+	if !strings.HasPrefix(r.Url, helmOciPrefix) {
+		return time.Time{}, nil
+	}
+	url, repository := helmOciSplitUrl(r.Url, chart, r.PlainHttp)
+	dockerRegistry := DockerRegistry{
+		Url:         url,
+		Credentials: r.Credentials,
+		Http:        r.Http,
+	}
+	client, url, err := dockerRegistry.createClient(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	manifest, err := helmOciFetchManifest(ctx, client, url, repository, helmOciTag(version))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if created, err := time.Parse(time.RFC3339, manifest.Annotations[helmOciCreatedAnnotation]); err == nil {
+		return created, nil
+	}
+	if manifest.Config.Digest == "" {
+		return time.Time{}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url+"/v2/"+repository+"/blobs/"+manifest.Config.Digest, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return time.Time{}, err
+	}
+	config := struct {
+		Created time.Time `json:"created"`
+	}{}
+	err = json.Unmarshal(respBody, &config)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return config.Created, nil
+}
+
+func helmOciTag(version string) string {
+	return strings.ReplaceAll(version, "+", "_")
+}
+
+func helmOciSplitUrl(ociUrl string, chart string, plainHttp bool) (string, string) {
+	scheme := "https://"
+	if plainHttp {
+		scheme = "http://"
+	}
+	hostAndPath := strings.Trim(strings.TrimPrefix(ociUrl, helmOciPrefix), "/")
+	segments := strings.SplitN(hostAndPath, "/", 2)
+	if len(segments) == 1 {
+		return scheme + segments[0], chart
+	}
+	return scheme + segments[0], segments[1] + "/" + chart
 }
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHelmOciSplitUrl(t *testing.T) {
+	url, repository := helmOciSplitUrl("oci://registry-1.docker.io/bitnamicharts", "nginx", false)
+	assert.Equal(t, "https://registry-1.docker.io", url)
+	assert.Equal(t, "bitnamicharts/nginx", repository)
+
+	url, repository = helmOciSplitUrl("oci://ghcr.io/org/charts/", "app", false)
+	assert.Equal(t, "https://ghcr.io", url)
+	assert.Equal(t, "org/charts/app", repository)
+
+	url, repository = helmOciSplitUrl("oci://localhost:5000", "app", true)
+	assert.Equal(t, "http://localhost:5000", url)
+	assert.Equal(t, "app", repository)
+}
+
+func TestHelmFetchVersionsOci(t *testing.T) {
+	manifestRequests := map[string]int{}
+	mutex := sync.Mutex{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			mutex.Lock()
+			manifestRequests[path.Base(r.URL.Path)]++
+			mutex.Unlock()
+		}
+		switch r.URL.Path {
+		case "/v2/charts/app/tags/list":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"charts/app","tags":["1.0.0","1.1.0_build.1","1.1.0-image","1.2.0"]}`))
+		case "/v2/charts/app/manifests/1.0.0", "/v2/charts/app/manifests/1.1.0_build.1", "/v2/charts/app/manifests/1.2.0":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.cncf.helm.config.v1+json"}}`))
+		case "/v2/charts/app/manifests/1.1.0-image":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.oci.image.config.v1+json"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := HelmRegistry{
+		Url:       "oci://" + strings.TrimPrefix(server.URL, "http://") + "/charts",
+		PlainHttp: true,
+	}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0+build.1", "1.2.0"}, output.Versions)
+		assert.Equal(t, []string{"1.1.0-image"}, output.Skipped)
+		assert.Equal(t, map[string]int{"1.0.0": 1, "1.1.0_build.1": 1, "1.1.0-image": 1, "1.2.0": 1}, manifestRequests)
+	}
+
+	// only tags that have not been seen before are looked at again
+	manifestRequests = map[string]int{}
+	published := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	output, err = reg.FetchVersionsIncremental(context.Background(), "app", &RegistryFetchResult{
+		Versions: []string{"1.0.0", "1.1.0+build.1"},
+		Metadata: map[string]VersionMetadata{"1.0.0": {Published: published}},
+		Skipped:  []string{"1.1.0-image"},
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0+build.1", "1.2.0"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{"1.0.0": {Published: published}}, output.Metadata)
+		assert.Equal(t, []string{"1.1.0-image"}, output.Skipped)
+		assert.Equal(t, map[string]int{"1.2.0": 1}, manifestRequests)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestHelmFetchPublishedOci(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/charts/app/manifests/1.0.0":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.cncf.helm.config.v1+json","digest":"sha256:aaa"},"annotations":{"org.opencontainers.image.created":"2024-01-02T03:04:05Z"}}`))
+		case "/v2/charts/app/manifests/1.1.0_build.1":
+			w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+			_, _ = w.Write([]byte(`{"schemaVersion":2,"config":{"mediaType":"application/vnd.cncf.helm.config.v1+json","digest":"sha256:bbb"}}`))
+		case "/v2/charts/app/blobs/sha256:bbb":
+			_, _ = w.Write([]byte(`{"name":"app","version":"1.1.0+build.1","created":"2024-02-03T04:05:06Z"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := HelmRegistry{
+		Url:       "oci://" + strings.TrimPrefix(server.URL, "http://") + "/charts",
+		PlainHttp: true,
+	}
+	published, err := reg.FetchPublished(context.Background(), "app", "1.0.0")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), published)
+	}
+	published, err = reg.FetchPublished(context.Background(), "app", "1.1.0+build.1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC), published)
+	}
+	_, err = reg.FetchPublished(context.Background(), "app", "2.0.0")
+	assert.Error(t, err)
+}
+
+func TestHelmFetchVersionsIndex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`apiVersion: v1
+entries:
+  app:
+  - name: app
+    version: 1.1.0
+    created: "2023-02-01T10:00:00.123456789Z"
+  - name: app
+    version: 1.0.0
+    created: "2023-01-01T10:00:00Z"
+`))
+	}))
+	defer server.Close()
+
+	reg := HelmRegistry{Url: server.URL}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "app")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.1.0", "1.0.0"}, output.Versions)
+		assert.Equal(t, time.Date(2023, 2, 1, 10, 0, 0, 123456789, time.UTC), output.Metadata["1.1.0"].Published)
+		assert.Equal(t, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), output.Metadata["1.0.0"].Published)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "other")
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ Registry = (*HttpJsonRegistry)(nil)
+
+type HttpJsonRegistry struct {
+	Interval    time.Duration
+	Url         string
+	Headers     map[string]string
+	Credentials HttpBasicCredentials
+	JsonPath    jsonPath
+	Http        HttpClientConfig
+}
+
+func (r HttpJsonRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r HttpJsonRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	url := strings.ReplaceAll(r.Url, "<resource>", resource)
+	LogDebug("Fetching versions from http-json registry %s", url)
+
+	username := r.Credentials.Username
+	password := r.Credentials.Password
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range r.Headers {
+		req.Header.Set(k, v)
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
+	}
+
+	var data interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	err = decoder.Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.JsonPath.EvaluateStrings(data)
+}
@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHttpJsonFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/tool/releases.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"releases":[{"version":"1.0.0"},{"version":"1.1.0"}]}`))
+	}))
+	defer server.Close()
+
+	path, err := parseJsonPath("$.releases[*].version")
+	assert.NoError(t, err)
+	reg := HttpJsonRegistry{
+		Url:      server.URL + "/<resource>/releases.json",
+		Headers:  map[string]string{"X-Api-Key": "key"},
+		JsonPath: *path,
+	}
+	output, err := reg.FetchVersions(context.Background(), "tool")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, output)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var _ MetadataRegistry = (*MavenRegistry)(nil)
+
+type MavenRegistry struct {
+	Interval    time.Duration
+	Url         string
+	Credentials HttpBasicCredentials
+	Http        HttpClientConfig
+}
+
+type mavenRegistryMetadata struct {
+	GroupId    string `xml:"groupId"`
+	ArtifactId string `xml:"artifactId"`
+	Versioning struct {
+		Latest      string   `xml:"latest"`
+		Release     string   `xml:"release"`
+		Versions    []string `xml:"versions>version"`
+		LastUpdated string   `xml:"lastUpdated"`
+	} `xml:"versioning"`
+}
+
+const mavenLastUpdatedLayout = "20060102150405"
+
+func (r MavenRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r MavenRegistry) FetchVersions(ctx context.Context, coordinates string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, coordinates)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r MavenRegistry) FetchVersionsWithMetadata(ctx context.Context, coordinates string) (*RegistryFetchResult, error) {
+	LogDebug("Fetching versions from maven registry %s", coordinates)
+	baseUrl := "https://repo1.maven.org/maven2"
+	if r.Url != "" {
+		baseUrl = strings.TrimSuffix(r.Url, "/")
+	}
+	coordinatesSegments := strings.Split(coordinates, ":")
+	if len(coordinatesSegments) != 2 || coordinatesSegments[0] == "" || coordinatesSegments[1] == "" {
+		return nil, fmt.Errorf("resource %s must be of the form groupId:artifactId", coordinates)
+	}
+	groupId := coordinatesSegments[0]
+	artifactId := coordinatesSegments[1]
+	url := fmt.Sprintf("%s/%s/%s/maven-metadata.xml", baseUrl, strings.ReplaceAll(groupId, ".", "/"), artifactId)
+
+	username := r.Credentials.Username
+	password := r.Credentials.Password
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
+	}
+
+	mavenMetadata := mavenRegistryMetadata{}
+	err = xml.Unmarshal(body, &mavenMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
+	versioning := mavenMetadata.Versioning
+	// lastUpdated only tells when the metadata file has been touched, so it
+	// belongs to the artifact and is no publish time of any of the versions
+	if lastUpdated, err := time.Parse(mavenLastUpdatedLayout, versioning.LastUpdated); err == nil {
+		result.LastUpdated = lastUpdated
+	} else if versioning.LastUpdated != "" {
+		LogDebug("Ignoring invalid lastUpdated %s of %s", versioning.LastUpdated, coordinates)
+	}
+	for _, version := range SliceUnique(versioning.Versions) {
+		version = strings.TrimSpace(version)
+		if version == "" {
+			continue
+		}
+		metadata := VersionMetadata{}
+		if version == versioning.Latest {
+			metadata.Tags = append(metadata.Tags, "latest")
+		}
+		if version == versioning.Release {
+			metadata.Tags = append(metadata.Tags, "release")
+		}
+		result.Versions = append(result.Versions, version)
+		result.Metadata[version] = metadata
+	}
+
+	return &result, nil
+}
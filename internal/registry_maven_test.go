@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMavenFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/maven2/io/opentelemetry/javaagent/opentelemetry-javaagent/maven-metadata.xml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+  <groupId>io.opentelemetry.javaagent</groupId>
+  <artifactId>opentelemetry-javaagent</artifactId>
+  <versioning>
+    <latest>2.1.0-SNAPSHOT</latest>
+    <release>2.0.0</release>
+    <versions>
+      <version>1.0.0</version>
+      <version>2.0.0</version>
+      <version>2.1.0-SNAPSHOT</version>
+    </versions>
+    <lastUpdated>20240102030405</lastUpdated>
+  </versioning>
+</metadata>`))
+	}))
+	defer server.Close()
+
+	reg := MavenRegistry{Url: server.URL + "/maven2/", Credentials: HttpBasicCredentials{Username: "user", Password: "pass"}}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "io.opentelemetry.javaagent:opentelemetry-javaagent")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "2.0.0", "2.1.0-SNAPSHOT"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{
+			"1.0.0":          {},
+			"2.0.0":          {Tags: []string{"release"}},
+			"2.1.0-SNAPSHOT": {Tags: []string{"latest"}},
+		}, output.Metadata)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), output.LastUpdated)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "io.opentelemetry.javaagent:unknown")
+	assert.Error(t, err)
+	_, err = reg.FetchVersions(context.Background(), "invalid")
+	assert.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ MetadataRegistry = (*NpmRegistry)(nil)
+var _ PublishTimeRegistry = (*NpmRegistry)(nil)
+
+type NpmRegistry struct {
+	Interval    time.Duration
+	Url         string
+	AccessToken string
+	Http        HttpClientConfig
+}
+
+type npmRegistryPackage struct {
+	Name     string                     `json:"name"`
+	Versions map[string]json.RawMessage `json:"versions"`
+	DistTags map[string]string          `json:"dist-tags"`
+	Time     map[string]string          `json:"time"`
+}
+
+func (r NpmRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r NpmRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r NpmRegistry) FetchVersions(ctx context.Context, pkg string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r NpmRegistry) FetchVersionsWithMetadata(ctx context.Context, pkg string) (*RegistryFetchResult, error) {
+	LogDebug("Fetching versions from npm registry %s", pkg)
+	baseUrl := "https://registry.npmjs.org"
+	if r.Url != "" {
+		baseUrl = strings.TrimSuffix(r.Url, "/")
+	}
+	// scoped packages keep their @ but need the slash to be escaped
+	url := baseUrl + "/" + strings.ReplaceAll(pkg, "/", "%2f")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.AccessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
+	}
+
+	npmPackage := npmRegistryPackage{}
+	err = json.Unmarshal(body, &npmPackage)
+	if err != nil {
+		return nil, err
+	}
+
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
+	for version := range npmPackage.Versions {
+		result.Versions = append(result.Versions, version)
+	}
+	sort.Strings(result.Versions)
+	for _, version := range result.Versions {
+		metadata := VersionMetadata{}
+		if published, err := time.Parse(time.RFC3339, npmPackage.Time[version]); err == nil {
+			metadata.Published = published
+		}
+		for tag, taggedVersion := range npmPackage.DistTags {
+			if taggedVersion == version {
+				metadata.Tags = append(metadata.Tags, tag)
+			}
+		}
+		sort.Strings(metadata.Tags)
+		result.Metadata[version] = metadata
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNpmFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/@scope%2fpkg" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"name": "@scope/pkg",
+			"dist-tags": {"latest": "1.1.0", "next": "2.0.0-rc.1"},
+			"versions": {"1.0.0": {}, "1.1.0": {}, "2.0.0-rc.1": {}},
+			"time": {"created": "2021-01-01T00:00:00.000Z", "1.0.0": "2021-01-01T00:00:00.000Z", "1.1.0": "2021-02-01T00:00:00.000Z"}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := NpmRegistry{Url: server.URL, AccessToken: "token"}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "@scope/pkg")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0", "2.0.0-rc.1"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{
+			"1.0.0":      {Published: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"1.1.0":      {Published: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), Tags: []string{"latest"}},
+			"2.0.0-rc.1": {Tags: []string{"next"}},
+		}, output.Metadata)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "unknown")
+	assert.Error(t, err)
+}
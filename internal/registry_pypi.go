@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ MetadataRegistry = (*PypiRegistry)(nil)
+var _ PublishTimeRegistry = (*PypiRegistry)(nil)
+
+// PypiRegistry lists releases with the JSON API of the index and falls back
+// to the simple API (PEP 691 or PEP 503) for indexes that do not provide it.
+// An url ending with /simple always uses the simple API.
+type PypiRegistry struct {
+	Interval    time.Duration
+	Url         string
+	Credentials HttpBasicCredentials
+	Http        HttpClientConfig
+}
+
+type pypiRegistryFile struct {
+	UploadTime *time.Time `json:"upload_time_iso_8601"`
+	Yanked     bool       `json:"yanked"`
+}
+
+type pypiRegistryProject struct {
+	Releases map[string][]pypiRegistryFile `json:"releases"`
+}
+
+type pypiRegistrySimpleProject struct {
+	Files []struct {
+		Filename   string          `json:"filename"`
+		UploadTime *time.Time      `json:"upload-time"`
+		Yanked     json.RawMessage `json:"yanked"`
+	} `json:"files"`
+}
+
+const pypiSimpleJsonMediaType = "application/vnd.pypi.simple.v1+json"
+
+var pypiNormalizeRegex = regexp.MustCompile(`[-_.]+`)
+var pypiSimpleAnchorRegex = regexp.MustCompile(`(?is)<a\s([^>]*)>([^<]*)</a>`)
+var pypiSdistExtensions = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tgz", ".zip"}
+
+func (r PypiRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+// ReportsPublished is true as both the JSON API and the PEP 691 simple API
+// list upload times. Indexes that only serve the PEP 503 HTML simple API do
+// not, which is only known once fetched.
+func (r PypiRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r PypiRegistry) FetchVersions(ctx context.Context, project string) ([]string, error) {
+	result, err := r.FetchVersionsWithMetadata(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	return result.Versions, nil
+}
+
+func (r PypiRegistry) FetchVersionsWithMetadata(ctx context.Context, project string) (*RegistryFetchResult, error) {
+	LogDebug("Fetching versions from pypi registry %s", project)
+	baseUrl := "https://pypi.org"
+	if r.Url != "" {
+		baseUrl = strings.TrimSuffix(r.Url, "/")
+	}
+	if strings.HasSuffix(baseUrl, "/simple") {
+		return r.fetchSimple(ctx, baseUrl, project)
+	}
+
+	url := fmt.Sprintf("%s/pypi/%s/json", baseUrl, pypiNormalizeName(project))
+	body, statusCode, _, err := r.get(ctx, url, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == http.StatusNotFound {
+		LogDebug("Pypi registry %s has no json api for %s, falling back to simple api", baseUrl, project)
+		return r.fetchSimple(ctx, baseUrl+"/simple", project)
+	}
+	if !(statusCode >= 200 && statusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, statusCode)
+	}
+
+	pypiProject := pypiRegistryProject{}
+	err = json.Unmarshal(body, &pypiProject)
+	if err != nil {
+		return nil, err
+	}
+	return pypiCollectReleases(project, pypiProject.Releases), nil
+}
+
+func (r PypiRegistry) fetchSimple(ctx context.Context, simpleUrl string, project string) (*RegistryFetchResult, error) {
+	url := fmt.Sprintf("%s/%s/", simpleUrl, pypiNormalizeName(project))
+	body, statusCode, contentType, err := r.get(ctx, url, pypiSimpleJsonMediaType+", text/html;q=0.1")
+	if err != nil {
+		return nil, err
+	}
+	if !(statusCode >= 200 && statusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, statusCode)
+	}
+
+	releases := map[string][]pypiRegistryFile{}
+	addFile := func(filename string, file pypiRegistryFile) {
+		version, ok := pypiVersionFromFilename(filename)
+		if !ok {
+			LogDebug("Skipping file %s of %s as its version is unknown", filename, project)
+			return
+		}
+		releases[version] = append(releases[version], file)
+	}
+	if strings.HasPrefix(contentType, pypiSimpleJsonMediaType) {
+		simpleProject := pypiRegistrySimpleProject{}
+		err = json.Unmarshal(body, &simpleProject)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range simpleProject.Files {
+			// yanked is either false or the reason why the file has been yanked
+			yanked := len(file.Yanked) > 0 && string(file.Yanked) != "false" && string(file.Yanked) != "null"
+			addFile(file.Filename, pypiRegistryFile{UploadTime: file.UploadTime, Yanked: yanked})
+		}
+	} else {
+		for _, match := range pypiSimpleAnchorRegex.FindAllStringSubmatch(string(body), -1) {
+			yanked := strings.Contains(strings.ToLower(match[1]), "data-yanked")
+			addFile(html.UnescapeString(strings.TrimSpace(match[2])), pypiRegistryFile{Yanked: yanked})
+		}
+	}
+	return pypiCollectReleases(project, releases), nil
+}
+
+func (r PypiRegistry) get(ctx context.Context, url string, accept string) ([]byte, int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
+	if err != nil {
+		return nil, 0, "", err
+	}
+	req.Header.Set("Accept", accept)
+	if r.Credentials.Username != "" {
+		req.SetBasicAuth(r.Credentials.Username, r.Credentials.Password)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, "", err
+	}
+	return body, resp.StatusCode, resp.Header.Get("Content-Type"), nil
+}
+
+func pypiCollectReleases(project string, releases map[string][]pypiRegistryFile) *RegistryFetchResult {
+	result := RegistryFetchResult{
+		Versions: []string{},
+		Metadata: map[string]VersionMetadata{},
+	}
+	for version, files := range releases {
+		// a release without files cannot be installed and one where all files
+		// have been yanked must not be installed
+		if len(files) == 0 {
+			LogDebug("Skipping version %s of %s as it has no files", version, project)
+			continue
+		}
+		yanked := true
+		metadata := VersionMetadata{}
+		for _, file := range files {
+			if !file.Yanked {
+				yanked = false
+			}
+			if file.UploadTime != nil && (metadata.Published.IsZero() || file.UploadTime.Before(metadata.Published)) {
+				metadata.Published = *file.UploadTime
+			}
+		}
+		if yanked {
+			LogDebug("Skipping version %s of %s as it has been yanked", version, project)
+			continue
+		}
+		result.Versions = append(result.Versions, version)
+		result.Metadata[version] = metadata
+	}
+	sort.Strings(result.Versions)
+	return &result
+}
+
+// pypiVersionFromFilename extracts the version from the name of a wheel
+// ({name}-{version}-...-{platform}.whl) or a source distribution
+// ({name}-{version}.tar.gz).
+func pypiVersionFromFilename(filename string) (string, bool) {
+	if strings.HasSuffix(filename, ".whl") {
+		parts := strings.Split(strings.TrimSuffix(filename, ".whl"), "-")
+		if len(parts) < 5 {
+			return "", false
+		}
+		return parts[1], true
+	}
+	for _, extension := range pypiSdistExtensions {
+		if strings.HasSuffix(filename, extension) {
+			stem := strings.TrimSuffix(filename, extension)
+			i := strings.LastIndex(stem, "-")
+			if i <= 0 || i == len(stem)-1 {
+				return "", false
+			}
+			return stem[i+1:], true
+		}
+	}
+	return "", false
+}
+
+func pypiNormalizeName(name string) string {
+	return strings.ToLower(pypiNormalizeRegex.ReplaceAllString(name, "-"))
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPypiFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/pypi/my-tool/json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{
+			"releases": {
+				"1.0.0": [
+					{"upload_time_iso_8601": "2021-01-02T00:00:00.000000Z", "yanked": false},
+					{"upload_time_iso_8601": "2021-01-01T00:00:00.000000Z", "yanked": false}
+				],
+				"1.1.0": [{"upload_time_iso_8601": "2021-02-01T00:00:00.000000Z", "yanked": true}],
+				"1.2.0": [
+					{"upload_time_iso_8601": "2021-03-01T00:00:00.000000Z", "yanked": true},
+					{"upload_time_iso_8601": "2021-03-02T00:00:00.000000Z", "yanked": false}
+				],
+				"1.3.0": []
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	reg := PypiRegistry{Url: server.URL}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "My_Tool")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.2.0"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{
+			"1.0.0": {Published: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+			"1.2.0": {Published: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)},
+		}, output.Metadata)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestPypiFetchVersionsSimple(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json/simple/my-tool/":
+			w.Header().Set("Content-Type", "application/vnd.pypi.simple.v1+json")
+			_, _ = w.Write([]byte(`{
+				"files": [
+					{"filename": "my_tool-1.0.0-py3-none-any.whl", "upload-time": "2021-01-02T00:00:00Z", "yanked": false},
+					{"filename": "my_tool-1.0.0.tar.gz", "upload-time": "2021-01-01T00:00:00Z"},
+					{"filename": "my_tool-1.1.0.tar.gz", "yanked": "broken"}
+				]
+			}`))
+		case "/html/simple/my-tool/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`<html><body>
+				<a href="../../files/my_tool-1.0.0.tar.gz#sha256=abc">my_tool-1.0.0.tar.gz</a>
+				<a href="../../files/my_tool-1.1.0.tar.gz" data-yanked="broken">my_tool-1.1.0.tar.gz</a>
+				<a href="../../files/my_tool-1.2.0-py3-none-any.whl">my_tool-1.2.0-py3-none-any.whl</a>
+			</body></html>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := PypiRegistry{Url: server.URL + "/json"}
+	output, err := reg.FetchVersionsWithMetadata(context.Background(), "My_Tool")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0"}, output.Versions)
+		assert.Equal(t, map[string]VersionMetadata{
+			"1.0.0": {Published: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		}, output.Metadata)
+	}
+
+	reg = PypiRegistry{Url: server.URL + "/html/simple/"}
+	output, err = reg.FetchVersionsWithMetadata(context.Background(), "My_Tool")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.2.0"}, output.Versions)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "unknown")
+	assert.Error(t, err)
+}
+
+func TestPypiVersionFromFilename(t *testing.T) {
+	version, ok := pypiVersionFromFilename("my_tool-1.0.0-py3-none-any.whl")
+	assert.True(t, ok)
+	assert.Equal(t, "1.0.0", version)
+	version, ok = pypiVersionFromFilename("my_tool-2.0.0rc1-1-cp312-cp312-manylinux_2_17_x86_64.whl")
+	assert.True(t, ok)
+	assert.Equal(t, "2.0.0rc1", version)
+	version, ok = pypiVersionFromFilename("my-tool-1.0.0.tar.gz")
+	assert.True(t, ok)
+	assert.Equal(t, "1.0.0", version)
+	_, ok = pypiVersionFromFilename("my_tool-1.0.0.exe")
+	assert.False(t, ok)
+}
+
+func TestPypiNormalizeName(t *testing.T) {
+	assert.Equal(t, "friendly-bard", pypiNormalizeName("Friendly-Bard"))
+	assert.Equal(t, "friendly-bard", pypiNormalizeName("FRIENDLY_BARD"))
+	assert.Equal(t, "friendly-bard", pypiNormalizeName("friendly.bard"))
+	assert.Equal(t, "friendly-bard", pypiNormalizeName("Friendly-._Bard"))
+}
@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+var _ Registry = (*StaticRegistry)(nil)
+
+type StaticRegistry struct {
+	Interval time.Duration
+	Versions map[string][]string
+	File     string
+}
+
+func (r StaticRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+func (r StaticRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	found := false
+	result := []string{}
+	if versions, ok := r.Versions[resource]; ok {
+		found = true
+		result = append(result, versions...)
+	}
+	if r.File != "" {
+		LogDebug("Reading versions from static registry file %s", r.File)
+		bytes, err := os.ReadFile(r.File)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read versions file: %w", err)
+		}
+		fileVersions := map[string][]string{}
+		err = readYaml(bytes, &fileVersions)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse versions file %s: %w", r.File, err)
+		}
+		if versions, ok := fileVersions[resource]; ok {
+			found = true
+			result = append(result, versions...)
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("resource %s could not be found", resource)
+	}
+	return SliceUnique(result), nil
+}
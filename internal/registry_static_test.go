@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticFetchVersions(t *testing.T) {
+	file, err := os.CreateTemp(os.TempDir(), "versions")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+	err = os.WriteFile(file.Name(), []byte("library/ubuntu:\n  - 22.04\n  - 24.04\nlibrary/nginx:\n  - 1.10.0\n"), 0o644)
+	assert.NoError(t, err)
+
+	reg := StaticRegistry{
+		Versions: map[string][]string{
+			"library/nginx": {"1.9.0", "1.10.0"},
+			"library/redis": {"7.0.0"},
+		},
+		File: file.Name(),
+	}
+
+	output, err := reg.FetchVersions(context.Background(), "library/ubuntu")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"22.04", "24.04"}, output)
+	}
+	output, err = reg.FetchVersions(context.Background(), "library/nginx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.9.0", "1.10.0"}, output)
+	}
+	output, err = reg.FetchVersions(context.Background(), "library/redis")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"7.0.0"}, output)
+	}
+	_, err = reg.FetchVersions(context.Background(), "library/unknown")
+	assert.EqualError(t, err, "resource library/unknown could not be found")
+
+	reg2 := StaticRegistry{File: file.Name() + ".missing"}
+	_, err = reg2.FetchVersions(context.Background(), "library/ubuntu")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+var _ Registry = (*TerraformRegistry)(nil)
+
+type TerraformRegistry struct {
+	Interval    time.Duration
+	Url         string
+	AccessToken string
+	Http        HttpClientConfig
+}
+
+type terraformRegistryVersion struct {
+	Version string `json:"version"`
+}
+
+type terraformRegistryModuleVersions struct {
+	Modules []struct {
+		Versions []terraformRegistryVersion `json:"versions"`
+	} `json:"modules"`
+}
+
+type terraformRegistryProviderVersions struct {
+	Versions []terraformRegistryVersion `json:"versions"`
+}
+
+func (r TerraformRegistry) GetInterval() time.Duration {
+	return r.Interval
+}
+
+// FetchVersions accepts either a module address namespace/name/provider or
+// a provider address namespace/type.
+func (r TerraformRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	LogDebug("Fetching versions from terraform registry %s", resource)
+	baseUrl := "https://registry.terraform.io"
+	if r.Url != "" {
+		baseUrl = strings.TrimSuffix(r.Url, "/")
+	}
+
+	segments := strings.Split(resource, "/")
+	for _, s := range segments {
+		if s == "" {
+			return nil, fmt.Errorf("resource %s is not a valid module or provider address", resource)
+		}
+	}
+	service := ""
+	if len(segments) == 3 {
+		service = "modules.v1"
+	} else if len(segments) == 2 {
+		service = "providers.v1"
+	} else {
+		return nil, fmt.Errorf("resource %s is not a valid module or provider address", resource)
+	}
+
+	serviceUrl, err := r.discoverService(ctx, baseUrl, service)
+	if err != nil {
+		return nil, err
+	}
+	body, err := r.get(ctx, serviceUrl+resource+"/versions")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []terraformRegistryVersion{}
+	if service == "modules.v1" {
+		moduleVersions := terraformRegistryModuleVersions{}
+		err = json.Unmarshal(body, &moduleVersions)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range moduleVersions.Modules {
+			versions = append(versions, m.Versions...)
+		}
+	} else {
+		providerVersions := terraformRegistryProviderVersions{}
+		err = json.Unmarshal(body, &providerVersions)
+		if err != nil {
+			return nil, err
+		}
+		versions = providerVersions.Versions
+	}
+
+	result := []string{}
+	for _, v := range versions {
+		result = append(result, v.Version)
+	}
+	result = SliceUnique(result)
+	sort.Strings(result)
+	return result, nil
+}
+
+// discoverService resolves the base url of a service (e.g. modules.v1) as
+// announced by the registry host in /.well-known/terraform.json.
+func (r TerraformRegistry) discoverService(ctx context.Context, baseUrl string, service string) (string, error) {
+	body, err := r.get(ctx, baseUrl+"/.well-known/terraform.json")
+	if err != nil {
+		return "", err
+	}
+	services := map[string]interface{}{}
+	err = json.Unmarshal(body, &services)
+	if err != nil {
+		return "", err
+	}
+	servicePath, ok := services[service].(string)
+	if !ok || servicePath == "" {
+		return "", fmt.Errorf("terraform registry %s does not support %s", baseUrl, service)
+	}
+
+	base, err := url.Parse(baseUrl + "/")
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(servicePath)
+	if err != nil {
+		return "", err
+	}
+	serviceUrl := base.ResolveReference(ref).String()
+	if !strings.HasSuffix(serviceUrl, "/") {
+		serviceUrl = serviceUrl + "/"
+	}
+	return serviceUrl, nil
+}
+
+func (r TerraformRegistry) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	client := r.Http.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+r.AccessToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !(resp.StatusCode >= 200 && resp.StatusCode < 300) {
+		return nil, fmt.Errorf("request GET %s failed with status code %d", url, resp.StatusCode)
+	}
+	return body, nil
+}
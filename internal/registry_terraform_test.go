@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerraformFetchVersions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/terraform.json":
+			_, _ = w.Write([]byte(`{"modules.v1": "/api/modules/v1/", "providers.v1": "/api/providers/v1/"}`))
+		case "/api/modules/v1/my-ns/vpc/aws/versions":
+			_, _ = w.Write([]byte(`{"modules": [{"versions": [{"version": "1.1.0"}, {"version": "1.0.0"}]}]}`))
+		case "/api/providers/v1/my-ns/my-provider/versions":
+			_, _ = w.Write([]byte(`{"versions": [{"version": "2.0.0", "protocols": ["5.0"]}, {"version": "2.1.0", "protocols": ["5.0"]}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reg := TerraformRegistry{Url: server.URL, AccessToken: "token"}
+	output, err := reg.FetchVersions(context.Background(), "my-ns/vpc/aws")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"1.0.0", "1.1.0"}, output)
+	}
+	output, err = reg.FetchVersions(context.Background(), "my-ns/my-provider")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"2.0.0", "2.1.0"}, output)
+	}
+
+	_, err = reg.FetchVersions(context.Background(), "my-ns/unknown")
+	assert.Error(t, err)
+	_, err = reg.FetchVersions(context.Background(), "invalid")
+	assert.Error(t, err)
+	_, err = TerraformRegistry{Url: server.URL}.FetchVersions(context.Background(), "my-ns/vpc/aws")
+	assert.Error(t, err)
+}
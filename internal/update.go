@@ -1,12 +1,14 @@
 package internal
 
 import (
+	"context"
 	utiljson "encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,11 +18,11 @@ type UpdateVersionResult struct {
 	Action *Action
 }
 
-func ApplyUpdate(dir string, config Config, cacheProvider CacheProvider, action Action, changeSet ChangeSet) error {
+func ApplyUpdate(ctx context.Context, dir string, config Config, cacheProvider CacheProvider, action Action, changeSet ChangeSet) error {
 	if len(changeSet.Changes) == 0 {
 		return fmt.Errorf("changeset must not be empty")
 	}
-	if err := action.Apply(dir, changeSet); err != nil {
+	if err := action.Apply(ctx, dir, changeSet); err != nil {
 		if changeSet.Group == "" {
 			return fmt.Errorf("%s: %w", changeSet.Group, err)
 		}
@@ -32,7 +34,38 @@ func ApplyUpdate(dir string, config Config, cacheProvider CacheProvider, action
 	return nil
 }
 
-func DetectUpdates(dir string, config Config, cacheProvider CacheProvider) []UpdateVersionResult {
+type detectUpdatesFile struct {
+	fileRel     string
+	fileFormat  FileFormat
+	lines       []string
+	err         error
+	annotations []detectUpdatesAnnotation
+}
+
+type detectUpdatesAnnotation struct {
+	lineNum    int
+	annotation *annotation
+	err        error
+}
+
+type registryResource struct {
+	RegistryName string
+	ResourceName string
+}
+
+type registryResourceVersions struct {
+	versions []string
+	metadata map[string]VersionMetadata
+	digests  map[string]string
+	// endpoint is the url that served the versions, if the registry can
+	// fall back to alternatives
+	endpoint string
+	err      error
+	// digestsErr only affects annotations that track tags by digest
+	digestsErr error
+}
+
+func DetectUpdates(ctx context.Context, dir string, config Config, cacheProvider CacheProvider) []UpdateVersionResult {
 	cacheKey := os.Getenv("GIT_OPS_UPDATE_CACHE_KEY")
 	cache, err := cacheProvider.Load()
 	if err != nil {
@@ -40,100 +73,190 @@ func DetectUpdates(dir string, config Config, cacheProvider CacheProvider) []Upd
 		cache = &Cache{}
 	}
 
+	for i, rule := range config.Ignore {
+		if rule.IsStale(time.Now()) {
+			LogWarning("Ignore rule %d is stale since it expired on %s and should be revisited", i, rule.Expires.Format("2006-01-02"))
+		}
+	}
+
 	files, err := fileList(dir, config.Files.Includes, config.Files.Excludes)
 	if err != nil {
 		return []UpdateVersionResult{{Error: err}}
 	}
 
-	result := []UpdateVersionResult{}
+	scannedFiles := []detectUpdatesFile{}
 	for _, file := range files {
-		fileRel, err := filepath.Rel(dir, file)
-		if err != nil {
-			result = append(result, UpdateVersionResult{Error: err})
-			continue
+		scannedFiles = append(scannedFiles, scanFile(dir, file, config))
+	}
+
+	// every distinct resource is only looked up once, no matter how many
+	// annotations reference it
+	resources := []registryResource{}
+	registries := map[registryResource]Registry{}
+	tags := map[registryResource][]string{}
+	for _, file := range scannedFiles {
+		for _, a := range file.annotations {
+			if a.annotation == nil {
+				continue
+			}
+			rr := registryResource{RegistryName: a.annotation.RegistryName, ResourceName: a.annotation.ResourceName}
+			if _, ok := registries[rr]; !ok {
+				resources = append(resources, rr)
+				registries[rr] = *a.annotation.Registry
+			}
+			if a.annotation.Tag != "" {
+				tags[rr] = SliceUnique(append(tags[rr], a.annotation.Tag))
+			}
 		}
-		LogDebug("Scanning file %s", fileRel)
+	}
 
-		bytes, err := os.ReadFile(file)
-		if err != nil {
-			result = append(result, UpdateVersionResult{Error: fmt.Errorf("%s: %w", fileRel, err)})
-			continue
+	available := map[registryResource]registryResourceVersions{}
+	pending := []registryResource{}
+	previous := map[registryResource]*RegistryFetchResult{}
+	for _, rr := range resources {
+		cachedResource := cache.FindResource(rr.RegistryName, rr.ResourceName)
+		if cachedResource != nil {
+			previous[rr] = &RegistryFetchResult{
+				Versions: cachedResource.Versions,
+				Metadata: cachedResource.Metadata,
+				Endpoint: cachedResource.Endpoint,
+				Skipped:  cachedResource.Skipped,
+			}
+		}
+		if cachedResource != nil && !cachedResourceHasDigests(*cachedResource, tags[rr]) {
+			LogDebug("Ignoring cached versions for %s/%s (digests of tracked tags missing)", rr.RegistryName, rr.ResourceName)
+			cachedResource = nil
 		}
-		lines := strings.Split(string(bytes), "\n")
+		if cachedResource != nil && cacheKey != "" && cachedResource.CacheKey == cacheKey {
+			LogDebug("Using cached versions for %s/%s (cache key hit)", rr.RegistryName, rr.ResourceName)
+			available[rr] = registryResourceVersions{versions: cachedResource.Versions, metadata: cachedResource.Metadata, digests: cachedResource.Digests, endpoint: cachedResource.Endpoint}
+		} else if cachedResource != nil && cachedResource.Timestamp.Add(time.Duration(registries[rr].GetInterval())).After(time.Now()) {
+			LogDebug("Using cached versions for %s/%s (cache interval hit)", rr.RegistryName, rr.ResourceName)
+			available[rr] = registryResourceVersions{versions: cachedResource.Versions, metadata: cachedResource.Metadata, digests: cachedResource.Digests, endpoint: cachedResource.Endpoint}
+		} else {
+			pending = append(pending, rr)
+		}
+	}
 
-		errs := []error{}
-		fileFormat, err := GuessFileFormatFromExtension(file)
-		if err != nil {
-			result = append(result, UpdateVersionResult{Error: fmt.Errorf("%s: %w", fileRel, err)})
+	fetchResults := fetchResources(ctx, pending, registries, tags, previous, config.RegistryConcurrency)
+	for i, rr := range pending {
+		fetchResult := fetchResults[i]
+		if fetchResult.err != nil {
+			available[rr] = registryResourceVersions{err: fetchResult.err}
 			continue
 		}
-		fileAnnotations, err := fileFormat.ExtractAnnotations(lines)
+		available[rr] = registryResourceVersions{versions: fetchResult.result.Versions, metadata: fetchResult.result.Metadata, digests: fetchResult.digests, endpoint: fetchResult.result.Endpoint, digestsErr: fetchResult.digestsErr}
+		nextCache := cache.UpdateResource(CacheResource{
+			RegistryName: rr.RegistryName,
+			ResourceName: rr.ResourceName,
+			Versions:     fetchResult.result.Versions,
+			Metadata:     fetchResult.result.Metadata,
+			Digests:      fetchResult.digests,
+			Endpoint:     fetchResult.result.Endpoint,
+			Skipped:      fetchResult.result.Skipped,
+			LastUpdated:  fetchResult.result.LastUpdated,
+			Timestamp:    fetchResult.timestamp,
+			CacheKey:     cacheKey,
+		})
+		cache = &nextCache
+	}
+	if len(pending) > 0 {
+		err = cacheProvider.Save(*cache)
 		if err != nil {
-			result = append(result, UpdateVersionResult{Error: fmt.Errorf("%s: %w", fileRel, err)})
+			for _, rr := range pending {
+				if available[rr].err == nil {
+					available[rr] = registryResourceVersions{err: err}
+				}
+			}
+		}
+	}
+
+	enriched := map[registryResource]bool{}
+	result := []UpdateVersionResult{}
+	for _, file := range scannedFiles {
+		if file.err != nil {
+			result = append(result, UpdateVersionResult{Error: file.err})
 			continue
 		}
+		fileRel := file.fileRel
+		fileFormat := file.fileFormat
+		lines := file.lines
 
-		for _, fileAnnotation := range fileAnnotations {
-			annotation, err := parseAnnotation(fileAnnotation.AnnotationRaw, config)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
+		errs := []error{}
+		for _, fileAnnotation := range file.annotations {
+			if fileAnnotation.err != nil {
+				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, fileAnnotation.err))
 				continue
 			}
+			annotation := fileAnnotation.annotation
 			if annotation == nil {
 				continue
 			}
 
-			var availableVersions []string
-			cachedResource := cache.FindResource(annotation.RegistryName, annotation.ResourceName)
-			if cachedResource != nil && cacheKey != "" && cachedResource.CacheKey == cacheKey {
-				LogDebug("Using cached versions for %s/%s (cache key hit)", annotation.RegistryName, annotation.ResourceName)
-				availableVersions = cachedResource.Versions
-			} else if cachedResource != nil && cachedResource.Timestamp.Add(time.Duration((*annotation.Registry).GetInterval())).After(time.Now()) {
-				LogDebug("Using cached versions for %s/%s (cache interval hit)", annotation.RegistryName, annotation.ResourceName)
-				availableVersions = cachedResource.Versions
+			rr := registryResource{RegistryName: annotation.RegistryName, ResourceName: annotation.ResourceName}
+			versions := available[rr]
+			if versions.err != nil {
+				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, versions.err))
+				continue
+			}
+			availableVersions := versions.versions
+			availableVersionsMetadata := versions.metadata
+
+			currentValue, err := fileFormat.ReadValue(lines, fileAnnotation.lineNum)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
+				continue
+			}
+			var currentVersion, nextVersion *string
+			if annotation.Tag != "" {
+				// floating tags are tracked by digest, so the digest takes the
+				// role of the version
+				if versions.digestsErr != nil {
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, versions.digestsErr))
+					continue
+				}
+				currentVersion, err = (*annotation.Format).(DigestFormat).ExtractDigest(currentValue)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
+					continue
+				}
+				digest, ok := versions.digests[annotation.Tag]
+				if !ok {
+					errs = append(errs, fmt.Errorf("%s:%d: no digest known for tag %s", fileRel, fileAnnotation.lineNum, annotation.Tag))
+					continue
+				}
+				nextVersion = &digest
 			} else {
-				LogDebug("Fetching new versions for %s/%s", annotation.RegistryName, annotation.ResourceName)
-				versions, err := (*annotation.Registry).FetchVersions(annotation.ResourceName)
+				currentVersion, err = (*annotation.Format).ExtractVersion(currentValue)
 				if err != nil {
-					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
 					continue
 				}
-				availableVersions = versions
-				nextCache := cache.UpdateResource(CacheResource{
-					RegistryName: annotation.RegistryName,
-					ResourceName: annotation.ResourceName,
-					Versions:     availableVersions,
-					Timestamp:    time.Now(),
-					CacheKey:     cacheKey,
-				})
-				cache = &nextCache
-				err = cacheProvider.Save(*cache)
+				availableVersions = filterIgnoredVersions(config.Ignore, annotation.Ignore, annotation.RegistryName, annotation.ResourceName, availableVersions)
+				if availableVersionsMetadata == nil {
+					availableVersionsMetadata = map[string]VersionMetadata{}
+					versions.metadata = availableVersionsMetadata
+					available[rr] = versions
+				}
+				resolved, err := resolvePublished(ctx, *annotation, versions.endpoint, *currentVersion, availableVersions, availableVersionsMetadata)
+				if resolved {
+					enriched[rr] = true
+				}
 				if err != nil {
-					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
+					continue
+				}
+				nextVersion, err = annotation.Policy.FindNext(*currentVersion, availableVersions, availableVersionsMetadata, annotation.Prefix, annotation.Suffix, annotation.Filter)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
 					continue
 				}
-			}
-
-			currentValue, err := fileFormat.ReadValue(lines, fileAnnotation.LineNum)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
-				continue
-			}
-			currentVersion, err := (*annotation.Format).ExtractVersion(currentValue)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
-				continue
-			}
-			nextVersion, err := annotation.Policy.FindNext(*currentVersion, availableVersions, annotation.Prefix, annotation.Suffix, annotation.Filter)
-			if err != nil {
-				errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
-				continue
 			}
 
 			if *currentVersion != *nextVersion {
-				nextValue, err := (*annotation.Format).ReplaceVersion(currentValue, *nextVersion)
+				nextValue, err := replaceVersion(ctx, *annotation, currentValue, *nextVersion)
 				if err != nil {
-					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.LineNum, err))
+					errs = append(errs, fmt.Errorf("%s:%d: %w", fileRel, fileAnnotation.lineNum, err))
 					continue
 				}
 				change := Change{
@@ -143,7 +266,7 @@ func DetectUpdates(dir string, config Config, cacheProvider CacheProvider) []Upd
 					NewVersion:   *nextVersion,
 					File:         fileRel,
 					FileFormat:   fileFormat,
-					LineNum:      fileAnnotation.LineNum,
+					LineNum:      fileAnnotation.lineNum,
 					OldValue:     currentValue,
 					NewValue:     *nextValue,
 					Exec:         annotation.Exec,
@@ -153,7 +276,7 @@ func DetectUpdates(dir string, config Config, cacheProvider CacheProvider) []Upd
 					augmenterMessages := []string{}
 					augmenterFooters := []string{}
 					for _, a := range config.Augmenters {
-						augmenterMessage, augmenterFooter, err := a.RenderMessage(config, change)
+						augmenterMessage, augmenterFooter, err := a.RenderMessage(ctx, config, change)
 						if err == nil {
 							if augmenterMessage != "" {
 								augmenterMessages = append(augmenterMessages, augmenterMessage)
@@ -175,9 +298,217 @@ func DetectUpdates(dir string, config Config, cacheProvider CacheProvider) []Upd
 		}
 	}
 
+	if len(enriched) > 0 {
+		for _, rr := range resources {
+			cachedResource := cache.FindResource(rr.RegistryName, rr.ResourceName)
+			if !enriched[rr] || cachedResource == nil {
+				continue
+			}
+			cachedResource.Metadata = available[rr].metadata
+			nextCache := cache.UpdateResource(*cachedResource)
+			cache = &nextCache
+		}
+		err = cacheProvider.Save(*cache)
+		if err != nil {
+			LogWarning("Unable to write cache: %v", err)
+		}
+	}
+
 	return result
 }
 
+// resolvePublished looks up missing publish times of the versions newer than
+// the current one, best first, until one is old enough for the policy's
+// minimum age, starting with the endpoint that served the versions. It
+// reports whether metadata has been added. Registries that
+// can neither list nor look up a publish time get a warning, as the minimum
+// age then holds back their versions for good.
+func resolvePublished(ctx context.Context, annotation annotation, endpoint string, currentVersion string, versions []string, metadata map[string]VersionMetadata) (bool, error) {
+	policy := *annotation.Policy
+	if policy.MinAge <= 0 {
+		return false, nil
+	}
+	publishedRegistry, canFetch := registryWithPreferredEndpoint(*annotation.Registry, endpoint).(PublishedRegistry)
+	candidates, err := policy.FilterAndSort(currentVersion, append(append([]string{}, versions...), currentVersion), metadata, annotation.Prefix, annotation.Suffix, annotation.Filter)
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	resolved := false
+	unknown := 0
+	for _, candidate := range candidates {
+		if policy.Compare(currentVersion, candidate, annotation.Prefix, annotation.Suffix) >= 0 {
+			break
+		}
+		m := metadata[candidate]
+		if m.Published.IsZero() && !canFetch {
+			unknown++
+			continue
+		}
+		if m.Published.IsZero() {
+			LogDebug("Fetching publish time for %s/%s:%s", annotation.RegistryName, annotation.ResourceName, candidate)
+			published, err := publishedRegistry.FetchPublished(ctx, annotation.ResourceName, candidate)
+			if err != nil {
+				return resolved, fmt.Errorf("unable to fetch publish time of %s:%s: %w", annotation.ResourceName, candidate, err)
+			}
+			m.Published = published
+			metadata[candidate] = m
+			resolved = true
+		}
+		if policy.IsOldEnough(m, now) {
+			break
+		}
+	}
+	if unknown > 0 {
+		LogWarning("Registry %s did not report when %d newer versions of %s have been published, which are skipped due to the minimum age of policy %s", annotation.RegistryName, unknown, annotation.ResourceName, annotation.PolicyName)
+	}
+	return resolved, nil
+}
+
+// replaceVersion puts the next version into the value and, for formats that
+// pin a digest, resolves the digest of the next version from the registry.
+// For tracked floating tags the version already is the next digest.
+func replaceVersion(ctx context.Context, annotation annotation, value string, version string) (*string, error) {
+	digestFormat, ok := (*annotation.Format).(DigestFormat)
+	if !ok {
+		return (*annotation.Format).ReplaceVersion(value, version)
+	}
+	if annotation.Tag != "" {
+		return digestFormat.ReplaceVersionWithDigest(value, annotation.Tag, version)
+	}
+	digestRegistry, ok := (*annotation.Registry).(DigestRegistry)
+	if !ok {
+		return nil, fmt.Errorf("registry %s does not support resolving digests", annotation.RegistryName)
+	}
+	LogDebug("Fetching digest for %s/%s:%s", annotation.RegistryName, annotation.ResourceName, version)
+	digest, err := digestRegistry.FetchDigest(ctx, annotation.ResourceName, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch digest of %s:%s: %w", annotation.ResourceName, version, err)
+	}
+	return digestFormat.ReplaceVersionWithDigest(value, version, digest)
+}
+
+func cachedResourceHasDigests(cachedResource CacheResource, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := cachedResource.Digests[tag]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func scanFile(dir string, file string, config Config) detectUpdatesFile {
+	fileRel, err := filepath.Rel(dir, file)
+	if err != nil {
+		return detectUpdatesFile{err: err}
+	}
+	LogDebug("Scanning file %s", fileRel)
+
+	bytes, err := os.ReadFile(file)
+	if err != nil {
+		return detectUpdatesFile{err: fmt.Errorf("%s: %w", fileRel, err)}
+	}
+	lines := strings.Split(string(bytes), "\n")
+
+	fileFormat, err := GuessFileFormatFromExtension(file)
+	if err != nil {
+		return detectUpdatesFile{err: fmt.Errorf("%s: %w", fileRel, err)}
+	}
+	fileAnnotations, err := fileFormat.ExtractAnnotations(lines)
+	if err != nil {
+		return detectUpdatesFile{err: fmt.Errorf("%s: %w", fileRel, err)}
+	}
+
+	result := detectUpdatesFile{
+		fileRel:    fileRel,
+		fileFormat: fileFormat,
+		lines:      lines,
+	}
+	for _, fileAnnotation := range fileAnnotations {
+		annotation, err := parseAnnotation(fileAnnotation.AnnotationRaw, config)
+		result.annotations = append(result.annotations, detectUpdatesAnnotation{
+			lineNum:    fileAnnotation.LineNum,
+			annotation: annotation,
+			err:        err,
+		})
+	}
+	return result
+}
+
+type fetchResourceResult struct {
+	result     *RegistryFetchResult
+	digests    map[string]string
+	digestsErr error
+	err        error
+	timestamp  time.Time
+}
+
+// fetchResources fetches all given resources concurrently, while never
+// running more than the configured number of requests against the same
+// registry at once. The results have the same order as the resources.
+func fetchResources(ctx context.Context, resources []registryResource, registries map[registryResource]Registry, tags map[registryResource][]string, previous map[registryResource]*RegistryFetchResult, concurrency map[string]int) []fetchResourceResult {
+	results := make([]fetchResourceResult, len(resources))
+	semaphores := map[string]chan struct{}{}
+	for _, rr := range resources {
+		if _, ok := semaphores[rr.RegistryName]; !ok {
+			limit, ok := concurrency[rr.RegistryName]
+			if !ok || limit <= 0 {
+				limit = defaultRegistryConcurrency
+			}
+			semaphores[rr.RegistryName] = make(chan struct{}, limit)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for i, rr := range resources {
+		wg.Add(1)
+		go func(i int, rr registryResource) {
+			defer wg.Done()
+			semaphore := semaphores[rr.RegistryName]
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				results[i] = fetchResourceResult{err: ctx.Err(), timestamp: time.Now()}
+				return
+			}
+			defer func() { <-semaphore }()
+
+			LogDebug("Fetching new versions for %s/%s", rr.RegistryName, rr.ResourceName)
+			result, err := fetchVersions(ctx, registries[rr], rr.ResourceName, previous[rr])
+			if err != nil {
+				results[i] = fetchResourceResult{err: err, timestamp: time.Now()}
+				return
+			}
+			digests, err := fetchDigests(ctx, registries[rr], rr.ResourceName, tags[rr])
+			results[i] = fetchResourceResult{result: result, digests: digests, digestsErr: err, timestamp: time.Now()}
+		}(i, rr)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func fetchDigests(ctx context.Context, registry Registry, resource string, tags []string) (map[string]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	digestRegistry, ok := registry.(DigestRegistry)
+	if !ok {
+		return nil, fmt.Errorf("registry does not support resolving digests")
+	}
+	result := map[string]string{}
+	for _, tag := range tags {
+		LogDebug("Fetching digest for %s:%s", resource, tag)
+		digest, err := digestRegistry.FetchDigest(ctx, resource, tag)
+		if err != nil {
+			return nil, fmt.Errorf("unable to fetch digest of %s:%s: %w", resource, tag, err)
+		}
+		result[tag] = digest
+	}
+	return result, nil
+}
+
 type annotation struct {
 	RegistryName string `json:"registry"`
 	Registry     *Registry
@@ -188,6 +519,10 @@ type annotation struct {
 	Format       *Format
 	ActionName   string `json:"action"`
 	Action       *Action
+	Tag          string   `json:"tag"`
+	Constraint   string   `json:"constraint"`
+	IgnoreRaw    []string `json:"ignore"`
+	Ignore       []VersionPattern
 	Prefix       string                 `json:"prefix"`
 	Suffix       string                 `json:"suffix"`
 	Filter       map[string]interface{} `json:"filter"`
@@ -222,20 +557,57 @@ func parseAnnotation(annotationStrFull string, config Config) (*annotation, erro
 		return nil, fmt.Errorf("annotation %s misses resource", annotationStr)
 	}
 
-	if annotation.PolicyName == "" {
-		return nil, fmt.Errorf("annotation %s misses policy", annotationStr)
+	// floating tags are followed by digest and need no policy
+	if annotation.Tag == "" {
+		if annotation.PolicyName == "" {
+			return nil, fmt.Errorf("annotation %s misses policy", annotationStr)
+		}
+		policy, ok := config.Policies[annotation.PolicyName]
+		if !ok {
+			return nil, fmt.Errorf("annotation %s references unknown policy %s", annotationStr, annotation.PolicyName)
+		}
+		if annotation.Constraint != "" {
+			constraint, err := ParseSemverConstraint(annotation.Constraint)
+			if err != nil {
+				return nil, fmt.Errorf("annotation %s has invalid constraint: %w", annotationStr, err)
+			}
+			policy, err = policy.WithSemverConstraint(constraint)
+			if err != nil {
+				return nil, fmt.Errorf("annotation %s references policy %s: %w", annotationStr, annotation.PolicyName, err)
+			}
+		}
+		if policy.MinAge > 0 && !registryReportsPublished(registry) {
+			return nil, fmt.Errorf("annotation %s references policy %s with a minimum age, but registry %s does not report publish times", annotationStr, annotation.PolicyName, annotation.RegistryName)
+		}
+		annotation.Policy = &policy
+	} else if annotation.Constraint != "" {
+		return nil, fmt.Errorf("annotation %s tracks tag %s and cannot have a constraint", annotationStr, annotation.Tag)
 	}
-	policy, ok := config.Policies[annotation.PolicyName]
-	if !ok {
-		return nil, fmt.Errorf("annotation %s references unknown policy %s", annotationStr, annotation.PolicyName)
+
+	for _, v := range annotation.IgnoreRaw {
+		pattern, err := ParseVersionPattern(v)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %s has invalid ignore: %w", annotationStr, err)
+		}
+		annotation.Ignore = append(annotation.Ignore, *pattern)
 	}
-	annotation.Policy = &policy
 
+	if annotation.Tag != "" && annotation.FormatName == "" {
+		annotation.FormatName = "docker-image-digest"
+	}
 	format, err := getFormat(annotation.FormatName)
 	if err != nil {
 		return nil, err
 	}
 	annotation.Format = format
+	if _, ok := (*format).(DigestFormat); !ok && annotation.Tag != "" {
+		return nil, fmt.Errorf("annotation %s tracks tag %s but format %s does not pin digests", annotationStr, annotation.Tag, annotation.FormatName)
+	}
+	if _, ok := (*format).(DigestFormat); ok {
+		if _, ok := registry.(DigestRegistry); !ok {
+			return nil, fmt.Errorf("annotation %s uses format %s but registry %s does not support resolving digests", annotationStr, annotation.FormatName, annotation.RegistryName)
+		}
+	}
 
 	action, err := getAction(config.Git.Provider, annotation.ActionName)
 	if err != nil {
@@ -1,7 +1,12 @@
 package internal
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
+	"sync"
 	"testing"
 	"time"
 
@@ -68,7 +73,7 @@ func TestDetectUpdates(t *testing.T) {
 		},
 	}
 
-	result := DetectUpdates(".", config, &cacheProvider)
+	result := DetectUpdates(context.Background(), ".", config, &cacheProvider)
 	if assert.Len(t, result, 6) {
 		if assert.NotNil(t, result[0].Change) {
 			change := result[0].Change
@@ -119,3 +124,355 @@ func TestDetectUpdates(t *testing.T) {
 		}
 	}
 }
+
+type countingRegistry struct {
+	mutex   *sync.Mutex
+	calls   map[string]int
+	running *int
+	maxRun  *int
+}
+
+func (r countingRegistry) GetInterval() time.Duration {
+	return time.Hour
+}
+
+func (r countingRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	r.mutex.Lock()
+	r.calls[resource] = r.calls[resource] + 1
+	*r.running = *r.running + 1
+	if *r.running > *r.maxRun {
+		*r.maxRun = *r.running
+	}
+	r.mutex.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	r.mutex.Lock()
+	*r.running = *r.running - 1
+	r.mutex.Unlock()
+	if resource == "broken" {
+		return nil, fmt.Errorf("broken")
+	}
+	return []string{"1.0.0", "1.1.0"}, nil
+}
+
+// detectUpdatesSemverPolicies takes whole values as semver versions
+var detectUpdatesSemverPolicies = map[string]Policy{
+	"semver": {
+		Pattern:  regexp.MustCompile(`^(?P<version>.*)$`),
+		Extracts: []Extract{{Value: "<version>", Strategy: SemverExtractStrategy{}}},
+	},
+}
+
+// detectUpdatesFixture writes the content to file.yaml in a new directory
+// and returns that directory together with a config that includes it and an
+// empty in-memory cache.
+func detectUpdatesFixture(t *testing.T, registries map[string]Registry, policies map[string]Policy, content string) (string, Config, *MemoryCacheProvider) {
+	t.Helper()
+	dir, err := os.MkdirTemp(os.TempDir(), "git-ops-update-")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "file.yaml"), []byte(content), 0o644))
+	config := Config{
+		Files: ConfigFiles{
+			Includes: []regexp.Regexp{*regexp.MustCompile(`\.yaml$`)},
+		},
+		Registries: registries,
+		Policies:   policies,
+	}
+	return dir, config, &MemoryCacheProvider{Cache: &Cache{}}
+}
+
+func TestDetectUpdatesParallel(t *testing.T) {
+	content := ""
+	for j := 0; j < 4; j++ {
+		content += fmt.Sprintf("r%d: 1.0.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res%d\",\"policy\":\"semver\"}\n", j, j)
+	}
+	content += "broken: 1.0.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"broken\",\"policy\":\"semver\"}\n"
+	registry := countingRegistry{mutex: &sync.Mutex{}, calls: map[string]int{}, running: new(int), maxRun: new(int)}
+	dir, config, cacheProvider := detectUpdatesFixture(t, map[string]Registry{"reg": registry}, detectUpdatesSemverPolicies, content)
+	config.RegistryConcurrency = map[string]int{"reg": 2}
+	files := []string{"file.yaml", "file1.yaml", "file2.yaml"}
+	for _, file := range files[1:] {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+	}
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	assert.Equal(t, map[string]int{"res0": 1, "res1": 1, "res2": 1, "res3": 1, "broken": 1}, registry.calls)
+	assert.LessOrEqual(t, *registry.maxRun, 2)
+	if assert.Len(t, result, 15) {
+		for i, file := range files {
+			for j := 0; j < 4; j++ {
+				change := result[i*5+j].Change
+				if assert.NotNil(t, change) {
+					assert.Equal(t, file, change.File)
+					assert.Equal(t, j+1, change.LineNum)
+					assert.Equal(t, "1.1.0", change.NewVersion)
+				}
+			}
+			assert.EqualError(t, result[i*5+4].Error, file+":5: broken")
+		}
+	}
+}
+
+type digestRegistry struct {
+	versions []string
+	digests  map[string]string
+}
+
+func (r digestRegistry) GetInterval() time.Duration {
+	return time.Hour
+}
+
+func (r digestRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	return r.versions, nil
+}
+
+func (r digestRegistry) FetchDigest(ctx context.Context, resource string, version string) (string, error) {
+	digest, ok := r.digests[version]
+	if !ok {
+		return "", fmt.Errorf("unknown version %s", version)
+	}
+	return digest, nil
+}
+
+func TestDetectUpdatesDigest(t *testing.T) {
+	content := "image: nginx:1.0.0@sha256:aaa # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"policy\":\"semver\",\"format\":\"docker-image-digest\"}\n"
+	registries := map[string]Registry{
+		"reg": digestRegistry{
+			versions: []string{"1.0.0", "1.1.0"},
+			digests:  map[string]string{"1.0.0": "sha256:aaa", "1.1.0": "sha256:bbb"},
+		},
+		"plain": countingRegistry{mutex: &sync.Mutex{}, calls: map[string]int{}, running: new(int), maxRun: new(int)},
+	}
+	dir, config, cacheProvider := detectUpdatesFixture(t, registries, detectUpdatesSemverPolicies, content)
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	if assert.Len(t, result, 1) && assert.NotNil(t, result[0].Change) {
+		assert.Equal(t, "1.1.0", result[0].Change.NewVersion)
+		assert.Equal(t, "nginx:1.0.0@sha256:aaa", result[0].Change.OldValue)
+		assert.Equal(t, "nginx:1.1.0@sha256:bbb", result[0].Change.NewValue)
+	}
+
+	_, err := parseAnnotation(`git-ops-update {"registry":"plain","resource":"res","policy":"semver","format":"docker-image-digest"}`, config)
+	assert.Error(t, err)
+}
+
+func TestDetectUpdatesTrackTag(t *testing.T) {
+	content := "a: nginx:latest@sha256:aaa # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"tag\":\"latest\"}\n" +
+		"b: nginx:stable@sha256:bbb # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"tag\":\"stable\"}\n" +
+		"c: nginx:mainline # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"tag\":\"mainline\"}\n"
+	registries := map[string]Registry{
+		"reg": digestRegistry{
+			versions: []string{"latest", "stable", "mainline"},
+			digests:  map[string]string{"latest": "sha256:ccc", "stable": "sha256:bbb", "mainline": "sha256:ddd"},
+		},
+	}
+	dir, config, cacheProvider := detectUpdatesFixture(t, registries, nil, content)
+	cacheProvider.Cache.Resources = []CacheResource{
+		{
+			RegistryName: "reg",
+			ResourceName: "library/nginx",
+			Versions:     []string{"latest", "stable"},
+			Digests:      map[string]string{"latest": "sha256:aaa", "stable": "sha256:bbb"},
+			Timestamp:    time.Now(),
+		},
+	}
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	if assert.Len(t, result, 2) {
+		if assert.NotNil(t, result[0].Change) {
+			assert.Equal(t, "sha256:aaa", result[0].Change.OldVersion)
+			assert.Equal(t, "sha256:ccc", result[0].Change.NewVersion)
+			assert.Equal(t, "nginx:latest@sha256:ccc", result[0].Change.NewValue)
+		}
+		if assert.NotNil(t, result[1].Change) {
+			assert.Equal(t, "", result[1].Change.OldVersion)
+			assert.Equal(t, "nginx:mainline@sha256:ddd", result[1].Change.NewValue)
+		}
+	}
+	cached := cacheProvider.Cache.FindResource("reg", "library/nginx")
+	if assert.NotNil(t, cached) {
+		assert.Equal(t, map[string]string{"latest": "sha256:ccc", "stable": "sha256:bbb", "mainline": "sha256:ddd"}, cached.Digests)
+	}
+
+	_, err := parseAnnotation(`git-ops-update {"registry":"reg","resource":"library/nginx","tag":"latest","format":"docker-image"}`, config)
+	assert.Error(t, err)
+}
+
+func TestDetectUpdatesTrackTagDigestError(t *testing.T) {
+	content := "a: nginx:missing@sha256:aaa # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"tag\":\"missing\"}\n" +
+		"b: nginx:1.0.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"library/nginx\",\"policy\":\"semver\",\"format\":\"docker-image\"}\n"
+	registries := map[string]Registry{
+		"reg": digestRegistry{
+			versions: []string{"1.0.0", "1.1.0"},
+			digests:  map[string]string{"1.0.0": "sha256:aaa", "1.1.0": "sha256:bbb"},
+		},
+	}
+	dir, config, cacheProvider := detectUpdatesFixture(t, registries, detectUpdatesSemverPolicies, content)
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	if assert.Len(t, result, 2) {
+		if assert.NotNil(t, result[0].Change) {
+			assert.Equal(t, "nginx:1.1.0", result[0].Change.NewValue)
+		}
+		assert.EqualError(t, result[1].Error, "file.yaml:1: unable to fetch digest of library/nginx:missing: unknown version missing")
+	}
+}
+
+// timelessRegistry claims to report publish times, but does not know any,
+// like a pypi index that only serves the html simple api
+type timelessRegistry struct {
+	countingRegistry
+}
+
+func (r timelessRegistry) ReportsPublished() bool {
+	return true
+}
+
+type publishedRegistry struct {
+	versions  []string
+	published map[string]time.Time
+	calls     *[]string
+}
+
+func (r publishedRegistry) GetInterval() time.Duration {
+	return time.Hour
+}
+
+func (r publishedRegistry) FetchVersions(ctx context.Context, resource string) ([]string, error) {
+	return r.versions, nil
+}
+
+func (r publishedRegistry) ReportsPublished() bool {
+	return true
+}
+
+func (r publishedRegistry) FetchPublished(ctx context.Context, resource string, version string) (time.Time, error) {
+	*r.calls = append(*r.calls, version)
+	return r.published[version], nil
+}
+
+func TestDetectUpdatesMinAge(t *testing.T) {
+	content := "image: 1.0.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"cooldown\"}\n"
+	now := time.Now()
+	calls := []string{}
+	registries := map[string]Registry{
+		"reg": publishedRegistry{
+			versions: []string{"0.9.0", "1.0.0", "1.1.0", "1.2.0", "1.3.0"},
+			published: map[string]time.Time{
+				"1.1.0": now.Add(-200 * time.Hour),
+				"1.2.0": now.Add(-100 * time.Hour),
+				"1.3.0": now.Add(-1 * time.Hour),
+			},
+			calls: &calls,
+		},
+	}
+	policies := map[string]Policy{
+		"cooldown": {
+			Pattern:  regexp.MustCompile(`^(?P<version>.*)$`),
+			Extracts: []Extract{{Value: "<version>", Strategy: SemverExtractStrategy{}}},
+			MinAge:   72 * time.Hour,
+		},
+	}
+	dir, config, _ := detectUpdatesFixture(t, registries, policies, content)
+	// looked up publish times have to survive a round trip through the file
+	cacheProvider := FileCacheProvider{File: filepath.Join(dir, ".cache.yaml")}
+
+	result := DetectUpdates(context.Background(), dir, config, &cacheProvider)
+	if assert.Len(t, result, 1) && assert.NotNil(t, result[0].Change) {
+		assert.Equal(t, "1.2.0", result[0].Change.NewVersion)
+	}
+	assert.Equal(t, []string{"1.3.0", "1.2.0"}, calls)
+	cache, err := cacheProvider.Load()
+	if assert.NoError(t, err) {
+		cached := cache.FindResource("reg", "res")
+		if assert.NotNil(t, cached) {
+			assert.WithinDuration(t, now.Add(-100*time.Hour), cached.Metadata["1.2.0"].Published, time.Millisecond)
+			assert.WithinDuration(t, now.Add(-1*time.Hour), cached.Metadata["1.3.0"].Published, time.Millisecond)
+		}
+	}
+
+	calls = []string{}
+	result = DetectUpdates(context.Background(), dir, config, &cacheProvider)
+	if assert.Len(t, result, 1) && assert.NotNil(t, result[0].Change) {
+		assert.Equal(t, "1.2.0", result[0].Change.NewVersion)
+	}
+	assert.Equal(t, []string{}, calls)
+
+	config.Registries["plain"] = countingRegistry{mutex: &sync.Mutex{}, calls: map[string]int{}, running: new(int), maxRun: new(int)}
+	_, err = parseAnnotation(`git-ops-update {"registry":"plain","resource":"res","policy":"cooldown"}`, config)
+	assert.EqualError(t, err, `annotation {"registry":"plain","resource":"res","policy":"cooldown"} references policy cooldown with a minimum age, but registry plain does not report publish times`)
+
+	config.Registries["timeless"] = timelessRegistry{countingRegistry: config.Registries["plain"].(countingRegistry)}
+	annotation, err := parseAnnotation(`git-ops-update {"registry":"timeless","resource":"res","policy":"cooldown"}`, config)
+	if assert.NoError(t, err) {
+		metadata := map[string]VersionMetadata{}
+		resolved, err := resolvePublished(context.Background(), *annotation, "", "1.0.0", []string{"1.0.0", "1.1.0"}, metadata)
+		assert.NoError(t, err)
+		assert.False(t, resolved)
+		next, err := annotation.Policy.FindNext("1.0.0", []string{"1.0.0", "1.1.0"}, metadata, "", "", nil)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "1.0.0", *next)
+		}
+	}
+}
+
+func TestDetectUpdatesConstraint(t *testing.T) {
+	content := "a: 1.24.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"semver\",\"constraint\":\"<1.27\"}\n" +
+		"b: 1.24.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"semver\"}\n" +
+		"c: 1.28.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"semver\",\"constraint\":\"~1.24\"}\n"
+	constraint, err := ParseSemverConstraint("<1.28")
+	assert.NoError(t, err)
+	registries := map[string]Registry{
+		"reg": digestRegistry{versions: []string{"1.24.0", "1.25.0", "1.26.3", "1.27.1", "1.28.0"}},
+	}
+	policies := map[string]Policy{
+		"semver": {
+			Pattern:  regexp.MustCompile(`^(?P<version>.*)$`),
+			Extracts: []Extract{{Value: "<version>", Strategy: SemverExtractStrategy{Constraint: constraint}}},
+		},
+	}
+	dir, config, cacheProvider := detectUpdatesFixture(t, registries, policies, content)
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	if assert.Len(t, result, 3) {
+		if assert.NotNil(t, result[0].Change) {
+			assert.Equal(t, "1.26.3", result[0].Change.NewVersion)
+		}
+		if assert.NotNil(t, result[1].Change) {
+			assert.Equal(t, "1.27.1", result[1].Change.NewVersion)
+		}
+		assert.EqualError(t, result[2].Error, "file.yaml:3: current version 1.28.0: 1.28.0 is outside of constraint ~1.24")
+	}
+
+	_, err = parseAnnotation(`git-ops-update {"registry":"reg","resource":"res","policy":"semver","constraint":">=abc"}`, config)
+	assert.Error(t, err)
+}
+
+func TestDetectUpdatesIgnore(t *testing.T) {
+	content := "a: 1.24.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"semver\"}\n" +
+		"b: 1.24.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"res\",\"policy\":\"semver\",\"ignore\":[\"1.26.*\"]}\n" +
+		"c: 1.24.0 # git-ops-update {\"registry\":\"reg\",\"resource\":\"other\",\"policy\":\"semver\"}\n"
+	registries := map[string]Registry{
+		"reg": digestRegistry{versions: []string{"1.24.0", "1.25.0", "1.26.0", "1.26.1", "1.27.0"}},
+	}
+	dir, config, cacheProvider := detectUpdatesFixture(t, registries, detectUpdatesSemverPolicies, content)
+	config.Ignore = []IgnoreRule{
+		{Registry: "reg", Resource: "res", Versions: []VersionPattern{{Glob: "1.27.0"}}, Expires: time.Now().Add(-time.Hour)},
+	}
+
+	result := DetectUpdates(context.Background(), dir, config, cacheProvider)
+	if assert.Len(t, result, 3) {
+		if assert.NotNil(t, result[0].Change) {
+			assert.Equal(t, "1.26.1", result[0].Change.NewVersion)
+		}
+		if assert.NotNil(t, result[1].Change) {
+			assert.Equal(t, "1.25.0", result[1].Change.NewVersion)
+		}
+		if assert.NotNil(t, result[2].Change) {
+			assert.Equal(t, "1.27.0", result[2].Change.NewVersion)
+		}
+	}
+
+	_, err := parseAnnotation(`git-ops-update {"registry":"reg","resource":"res","policy":"semver","ignore":["regexp:("]}`, config)
+	assert.Error(t, err)
+}
@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 func fileList(dir string, includes []regexp.Regexp, excludes []regexp.Regexp) ([]string, error) {
@@ -54,6 +56,19 @@ func validateName(name string) bool {
 	return nameRegex.MatchString(name)
 }
 
+// sleepContext waits for the given duration, but returns early with the
+// context error if the context is done before.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 func runCallbacks(callbacks []func() error) error {
 	for _, cb := range callbacks {
 		err := cb()